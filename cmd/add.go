@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/util"
+)
+
+// AddCommand lists the components of "add" command.
+type AddCommand struct {
+	fs    *flag.FlagSet
+	paths []string
+}
+
+// NewAddCommand creates a new command object.
+func NewAddCommand() *AddCommand {
+	return &AddCommand{
+		fs: flag.NewFlagSet("add", flag.ExitOnError),
+	}
+}
+
+// Name gives the name of the command.
+func (cmd *AddCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *AddCommand) Description() string {
+	return "Add file contents to the index"
+}
+
+// Init initializes and validates the given command.
+func (cmd *AddCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() < 1 {
+		return errors.New("Error: Missing <path> argument\n")
+	}
+
+	cmd.paths = cmd.fs.Args()
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *AddCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s <path>...\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *AddCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	for _, path := range cmd.paths {
+		util.Check(repo.Add(path))
+	}
+}
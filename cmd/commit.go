@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/util"
+)
+
+// CommitCommand lists the components of "commit" command.
+type CommitCommand struct {
+	fs         *flag.FlagSet
+	message    string
+	sign       bool
+	signingKey string
+	parent     string
+}
+
+// NewCommitCommand creates a new command object.
+func NewCommitCommand() *CommitCommand {
+	cmd := &CommitCommand{
+		fs: flag.NewFlagSet("commit", flag.ExitOnError),
+	}
+
+	cmd.fs.StringVar(&cmd.message, "m", "", "Commit message")
+	cmd.fs.BoolVar(&cmd.sign, "sign", false, "Sign the commit with an openpgp key")
+	cmd.fs.StringVar(&cmd.signingKey, "signing-key", "", "Path to an armored PGP private key used with -sign")
+	cmd.fs.StringVar(&cmd.parent, "parent", "HEAD", "Commit hash to use as the parent, empty for no parent")
+	return cmd
+}
+
+// Name gives the name of the command.
+func (cmd *CommitCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *CommitCommand) Description() string {
+	return "Record changes staged in the index to the repository"
+}
+
+// Init initializes and validates the given command.
+func (cmd *CommitCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.message == "" {
+		return errors.New("Error: Missing -m <message> argument\n")
+	}
+
+	if cmd.sign && cmd.signingKey == "" {
+		return errors.New("Error: -sign requires -signing-key\n")
+	}
+
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *CommitCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s -m <message> [-sign -signing-key <path>]\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *CommitCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	parent := ""
+	if cmd.parent != "" {
+		if hash, err := repo.UniqueNameResolve(cmd.parent); err == nil {
+			parent = hash
+		}
+	}
+
+	var commit *git.Commit
+	if cmd.sign {
+		keyData, err := ioutil.ReadFile(cmd.signingKey)
+		util.Check(err)
+
+		passphrase := os.Getenv("GOGIT_SIGNING_PASSPHRASE")
+		signer, err := git.NewOpenPGPSigner(keyData, passphrase)
+		util.Check(err)
+
+		commit, err = repo.CommitIndexSigned(parent, cmd.message, git.CommitOptions{Signer: signer})
+		util.Check(err)
+	} else {
+		commit, err = repo.CommitIndex(parent, cmd.message)
+		util.Check(err)
+	}
+
+	commitHash, err := repo.ObjectWrite(commit.Object, true)
+	util.Check(err)
+
+	util.Check(repo.UpdateBranchRef(commitHash))
+	fmt.Println(commitHash)
+}
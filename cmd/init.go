@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/util"
+)
+
+// InitCommand lists the components of "init" command.
+type InitCommand struct {
+	fs           *flag.FlagSet
+	objectFormat string
+	path         string
+}
+
+// NewInitCommand creates a new command object.
+func NewInitCommand() *InitCommand {
+	cmd := &InitCommand{
+		fs: flag.NewFlagSet("init", flag.ExitOnError),
+	}
+
+	cmd.fs.StringVar(&cmd.objectFormat, "object-format", "sha1",
+		"Hash algorithm new objects are addressed with (sha1 or sha256)")
+	return cmd
+}
+
+// Name gives the name of the command.
+func (cmd *InitCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *InitCommand) Description() string {
+	return "Create an empty git repository"
+}
+
+// Init initializes and validates the given command.
+func (cmd *InitCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() > 1 {
+		return errors.New("Error: Too many arguments\n")
+	}
+
+	cmd.path = "."
+	if cmd.fs.NArg() == 1 {
+		cmd.path = cmd.fs.Arg(0)
+	}
+
+	if _, err := git.ParseObjectFormat(cmd.objectFormat); err != nil {
+		return fmt.Errorf("Error: %v\n", err)
+	}
+
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *InitCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s [-object-format sha1|sha256] [<path>]\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *InitCommand) Execute() {
+	repo, err := git.NewRepo(cmd.path)
+	util.Check(err)
+
+	format, err := git.ParseObjectFormat(cmd.objectFormat)
+	util.Check(err)
+
+	// WriteObjectFormatExtension is the only place "extensions.objectFormat"
+	// ever gets recorded; every later command re-derives the format from
+	// this config entry via Repo.ObjectFormat().
+	util.Check(repo.WriteObjectFormatExtension(format))
+	fmt.Printf("Initialized empty Git repository in %s\n", cmd.path)
+}
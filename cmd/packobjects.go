@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/git/packfile"
+	"github.com/ssrathi/gogit/util"
+)
+
+// PackObjectsCommand lists the components of "pack-objects" command.
+type PackObjectsCommand struct {
+	fs *flag.FlagSet
+}
+
+// NewPackObjectsCommand creates a new command object.
+func NewPackObjectsCommand() *PackObjectsCommand {
+	return &PackObjectsCommand{
+		fs: flag.NewFlagSet("pack-objects", flag.ExitOnError),
+	}
+}
+
+// Name gives the name of the command.
+func (cmd *PackObjectsCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *PackObjectsCommand) Description() string {
+	return "Create a packed archive of objects read from stdin, one hash per line"
+}
+
+// Init initializes and validates the given command.
+func (cmd *PackObjectsCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() != 0 {
+		return errors.New("Error: pack-objects takes no positional arguments\n")
+	}
+
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *PackObjectsCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: <object hashes, one per line> | %s\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *PackObjectsCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	var entries []packfile.ObjectEntry
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		hash := scanner.Text()
+		if hash == "" {
+			continue
+		}
+
+		objHash, err := repo.ObjectFind(hash)
+		util.Check(err)
+
+		obj, err := repo.ObjectParse(objHash)
+		util.Check(err)
+
+		entries = append(entries, packfile.ObjectEntry{
+			Hash: objHash,
+			Type: obj.ObjType,
+			Data: obj.ObjData,
+		})
+	}
+	util.Check(scanner.Err())
+
+	packSHA, err := repo.WritePackObjects(entries)
+	util.Check(err)
+
+	fmt.Println(packSHA)
+}
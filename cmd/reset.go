@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/util"
+)
+
+// ResetCommand lists the components of "reset" command.
+type ResetCommand struct {
+	fs     *flag.FlagSet
+	soft   bool
+	mixed  bool
+	hard   bool
+	target string
+}
+
+// NewResetCommand creates a new command object.
+func NewResetCommand() *ResetCommand {
+	cmd := &ResetCommand{
+		fs: flag.NewFlagSet("reset", flag.ExitOnError),
+	}
+
+	cmd.fs.BoolVar(&cmd.soft, "soft", false, "Move HEAD only, leaving the index and work tree untouched")
+	cmd.fs.BoolVar(&cmd.mixed, "mixed", false, "Move HEAD and reset the index (default)")
+	cmd.fs.BoolVar(&cmd.hard, "hard", false, "Move HEAD, reset the index and overwrite the work tree")
+	return cmd
+}
+
+// Name gives the name of the command.
+func (cmd *ResetCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *ResetCommand) Description() string {
+	return "Reset current HEAD to the specified state"
+}
+
+// Init initializes and validates the given command.
+func (cmd *ResetCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() != 1 {
+		return errors.New("Error: Missing <rev> argument\n")
+	}
+
+	modesGiven := 0
+	for _, given := range []bool{cmd.soft, cmd.mixed, cmd.hard} {
+		if given {
+			modesGiven++
+		}
+	}
+	if modesGiven > 1 {
+		return errors.New("Error: Only one of -soft, -mixed or -hard may be given\n")
+	}
+
+	cmd.target = cmd.fs.Arg(0)
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *ResetCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s [-soft|-mixed|-hard] <rev>\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *ResetCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	mode := git.MixedReset
+	switch {
+	case cmd.soft:
+		mode = git.SoftReset
+	case cmd.hard:
+		mode = git.HardReset
+	}
+
+	util.Check(repo.Reset(cmd.target, mode))
+}
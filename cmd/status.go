@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/git/trie"
+	"github.com/ssrathi/gogit/util"
+)
+
+// StatusCommand lists the components of "status" command.
+type StatusCommand struct {
+	fs *flag.FlagSet
+}
+
+// NewStatusCommand creates a new command object.
+func NewStatusCommand() *StatusCommand {
+	return &StatusCommand{
+		fs: flag.NewFlagSet("status", flag.ExitOnError),
+	}
+}
+
+// Name gives the name of the command.
+func (cmd *StatusCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *StatusCommand) Description() string {
+	return "Show the working tree status"
+}
+
+// Init initializes and validates the given command.
+func (cmd *StatusCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() != 0 {
+		return errors.New("Error: status takes no arguments\n")
+	}
+
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *StatusCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *StatusCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	changes, err := repo.Status()
+	util.Check(err)
+
+	fmt.Println("Changes staged for commit:")
+	for _, c := range changes {
+		if c.Side == trie.IndexHead {
+			fmt.Printf("\t%s:\t%s\n", c.Action, c.Path)
+		}
+	}
+
+	fmt.Println("\nChanges not staged for commit:")
+	for _, c := range changes {
+		if c.Side == trie.WorkTreeIndex && c.Action != trie.Added {
+			fmt.Printf("\t%s:\t%s\n", c.Action, c.Path)
+		}
+	}
+
+	fmt.Println("\nUntracked files:")
+	for _, c := range changes {
+		if c.Side == trie.WorkTreeIndex && c.Action == trie.Added {
+			fmt.Printf("\t%s\n", c.Path)
+		}
+	}
+}
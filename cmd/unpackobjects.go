@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/util"
+)
+
+// UnpackObjectsCommand lists the components of "unpack-objects" command.
+type UnpackObjectsCommand struct {
+	fs       *flag.FlagSet
+	packPath string
+}
+
+// NewUnpackObjectsCommand creates a new command object.
+func NewUnpackObjectsCommand() *UnpackObjectsCommand {
+	return &UnpackObjectsCommand{
+		fs: flag.NewFlagSet("unpack-objects", flag.ExitOnError),
+	}
+}
+
+// Name gives the name of the command.
+func (cmd *UnpackObjectsCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *UnpackObjectsCommand) Description() string {
+	return "Unpack objects from a packfile into loose objects"
+}
+
+// Init initializes and validates the given command.
+func (cmd *UnpackObjectsCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() != 1 {
+		return errors.New("Error: Missing <pack-file> argument\n")
+	}
+
+	cmd.packPath = cmd.fs.Arg(0)
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *UnpackObjectsCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s <pack-file>\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *UnpackObjectsCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	count, err := repo.UnpackObjects(cmd.packPath)
+	util.Check(err)
+
+	fmt.Printf("Unpacked %d objects\n", count)
+}
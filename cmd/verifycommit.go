@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ssrathi/gogit/git"
+	"github.com/ssrathi/gogit/util"
+)
+
+// VerifyCommitCommand lists the components of "verify-commit" command.
+type VerifyCommitCommand struct {
+	fs       *flag.FlagSet
+	keyring  string
+	revision string
+}
+
+// NewVerifyCommitCommand creates a new command object.
+func NewVerifyCommitCommand() *VerifyCommitCommand {
+	cmd := &VerifyCommitCommand{
+		fs: flag.NewFlagSet("verify-commit", flag.ExitOnError),
+	}
+
+	cmd.fs.StringVar(&cmd.keyring, "keyring", "", "Path to an armored PGP public keyring to verify against")
+	return cmd
+}
+
+// Name gives the name of the command.
+func (cmd *VerifyCommitCommand) Name() string {
+	return cmd.fs.Name()
+}
+
+// Description gives the description of the command.
+func (cmd *VerifyCommitCommand) Description() string {
+	return "Check the GPG signature of a commit"
+}
+
+// Init initializes and validates the given command.
+func (cmd *VerifyCommitCommand) Init(args []string) error {
+	cmd.fs.Usage = cmd.Usage
+	if err := cmd.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd.fs.NArg() < 1 {
+		return errors.New("Error: Missing <commit> argument\n")
+	}
+	if cmd.keyring == "" {
+		return errors.New("Error: Missing -keyring <path> argument\n")
+	}
+
+	cmd.revision = cmd.fs.Arg(0)
+	return nil
+}
+
+// Usage prints the usage string for the end user.
+func (cmd *VerifyCommitCommand) Usage() {
+	fmt.Printf("%s - %s\n", cmd.Name(), cmd.Description())
+	fmt.Printf("usage: %s -keyring <path> <commit>\n", cmd.Name())
+	cmd.fs.PrintDefaults()
+}
+
+// Execute runs the given command till completion.
+func (cmd *VerifyCommitCommand) Execute() {
+	repo, err := git.GetRepo(".")
+	util.Check(err)
+
+	commitHash, err := repo.ObjectFind(cmd.revision)
+	util.Check(err)
+
+	obj, err := repo.ObjectParse(commitHash)
+	util.Check(err)
+
+	keyringData, err := ioutil.ReadFile(cmd.keyring)
+	util.Check(err)
+
+	verifier, err := git.NewOpenPGPVerifier(keyringData)
+	util.Check(err)
+
+	if err := git.VerifyCommit(obj, verifier); err != nil {
+		fmt.Printf("Bad signature on commit %s: %s\n", commitHash, err)
+		return
+	}
+
+	fmt.Printf("Good signature on commit %s\n", commitHash)
+}
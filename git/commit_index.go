@@ -0,0 +1,36 @@
+package git
+
+import "github.com/ssrathi/gogit/git/index"
+
+// buildTreeFromIndex writes one tree object per directory idx's paths
+// imply (deepest first) and returns the hash of the root tree, via a
+// fresh TreeBuilder. This is the mechanism NewCommitFromParams-driven
+// commits use instead of the caller hand-assembling a NewTreeFromInput
+// string.
+func (repo *Repo) buildTreeFromIndex(idx *index.Index) (string, error) {
+	builder := NewTreeBuilder(repo)
+	for _, e := range idx.Entries {
+		if err := builder.Insert(e.Path, ModeFile, e.Hash); err != nil {
+			return "", err
+		}
+	}
+	return builder.Write()
+}
+
+// CommitIndex builds a tree object from the current index and creates
+// a commit on top of it with the given parent (empty for the first
+// commit) and message, the same commit "gogit add" staged changes are
+// meant to be turned into.
+func (repo *Repo) CommitIndex(parent, msg string) (*Commit, error) {
+	idx, err := repo.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	treeHash, err := repo.buildTreeFromIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCommitFromParams(repo, treeHash, parent, msg)
+}
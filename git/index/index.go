@@ -0,0 +1,272 @@
+/*
+Package index implements Git's binary ".git/index" (staging area)
+format, version 2: a 12 byte header ("DIRC" signature, version, entry
+count), followed by the entries themselves sorted by path, followed by
+a trailing checksum over everything before it.
+*/
+package index
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// signature is the 4 byte magic at the start of every index file.
+const signature = "DIRC"
+
+// version is the only index format gogit knows how to read/write.
+const version = 2
+
+// Format describes the sizes an index file's binary layout uses for a
+// given object hash algorithm, mirroring packfile.Format: index has no
+// notion of "git object format" of its own, so a caller (Repo.Index/
+// Repo.WriteIndex) threads the repo's format straight through.
+type Format struct {
+	HashSize int
+	NewHash  func() hash.Hash
+}
+
+// SHA1Format is the layout every gogit repo used before SHA256 support
+// existed.
+var SHA1Format = Format{HashSize: sha1.Size, NewHash: sha1.New}
+
+// SHA256Format is used by repos initialized with "gogit init
+// --object-format=sha256".
+var SHA256Format = Format{HashSize: sha256.Size, NewHash: sha256.New}
+
+// entryBaseSize returns the size, in bytes, of an entry's fixed-length
+// fields (everything up to and including the format's hash and the 2
+// byte flags), before the variable-length, NUL-padded path.
+func entryBaseSize(format Format) int {
+	return 40 + format.HashSize + 2
+}
+
+// Entry is a single staged file: its last-known stat info (used only
+// to detect a dirty worktree quickly, the same way git does) plus the
+// hash of its staged content.
+type Entry struct {
+	CTimeSec, CTimeNano uint32
+	MTimeSec, MTimeNano uint32
+	Dev, Ino            uint32
+	Mode                uint32
+	UID, GID            uint32
+	Size                uint32
+	Hash                string // HashSize*2 hex chars, per the repo's object format
+	Path                string
+}
+
+// Index is the in-memory form of ".git/index": a flat, path-sorted
+// list of staged entries.
+type Index struct {
+	Entries []Entry
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{}
+}
+
+// Add inserts or replaces the entry for entry.Path, keeping Entries
+// sorted by path as the on-disk format requires.
+func (idx *Index) Add(entry Entry) {
+	for i, e := range idx.Entries {
+		if e.Path == entry.Path {
+			idx.Entries[i] = entry
+			return
+		}
+	}
+
+	idx.Entries = append(idx.Entries, entry)
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		return idx.Entries[i].Path < idx.Entries[j].Path
+	})
+}
+
+// Remove drops the entry for path, if present.
+func (idx *Index) Remove(path string) {
+	for i, e := range idx.Entries {
+		if e.Path == path {
+			idx.Entries = append(idx.Entries[:i], idx.Entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Get returns the entry for path, if staged.
+func (idx *Index) Get(path string) (Entry, bool) {
+	for _, e := range idx.Entries {
+		if e.Path == path {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Read parses an index file from path, whose entries are assumed to
+// use format (the repo's object format). A missing file is treated as
+// an empty index, matching a freshly initialized repo with nothing
+// staged yet.
+func Read(path string, format Format) (*Index, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12+format.HashSize {
+		return nil, fmt.Errorf("index: truncated index file %q", path)
+	}
+
+	body, trailer := data[:len(data)-format.HashSize], data[len(data)-format.HashSize:]
+	sum := format.NewHash()
+	sum.Write(body)
+	if !bytes.Equal(sum.Sum(nil), trailer) {
+		return nil, fmt.Errorf("index: checksum mismatch in %q", path)
+	}
+
+	if string(body[0:4]) != signature {
+		return nil, fmt.Errorf("index: bad signature in %q", path)
+	}
+	if v := binary.BigEndian.Uint32(body[4:8]); v != version {
+		return nil, fmt.Errorf("index: unsupported version %d", v)
+	}
+	count := binary.BigEndian.Uint32(body[8:12])
+
+	idx := New()
+	off := 12
+	for i := uint32(0); i < count; i++ {
+		entry, n, err := parseEntry(body[off:], format)
+		if err != nil {
+			return nil, err
+		}
+		idx.Entries = append(idx.Entries, entry)
+		off += n
+	}
+
+	return idx, nil
+}
+
+// parseEntry decodes a single entry starting at b[0], returning the
+// entry and the number of bytes (including NUL padding) it occupied.
+func parseEntry(b []byte, format Format) (Entry, int, error) {
+	base := entryBaseSize(format)
+	if len(b) < base {
+		return Entry{}, 0, fmt.Errorf("index: truncated entry")
+	}
+
+	e := Entry{
+		CTimeSec:  binary.BigEndian.Uint32(b[0:4]),
+		CTimeNano: binary.BigEndian.Uint32(b[4:8]),
+		MTimeSec:  binary.BigEndian.Uint32(b[8:12]),
+		MTimeNano: binary.BigEndian.Uint32(b[12:16]),
+		Dev:       binary.BigEndian.Uint32(b[16:20]),
+		Ino:       binary.BigEndian.Uint32(b[20:24]),
+		Mode:      binary.BigEndian.Uint32(b[24:28]),
+		UID:       binary.BigEndian.Uint32(b[28:32]),
+		GID:       binary.BigEndian.Uint32(b[32:36]),
+		Size:      binary.BigEndian.Uint32(b[36:40]),
+		Hash:      fmt.Sprintf("%x", b[40:40+format.HashSize]),
+	}
+
+	nameLen := int(binary.BigEndian.Uint16(b[base-2:base]) & 0x0fff)
+	nameEnd := base + nameLen
+	if nameEnd > len(b) {
+		return Entry{}, 0, fmt.Errorf("index: truncated entry path")
+	}
+	e.Path = string(b[base:nameEnd])
+
+	// Entries are NUL-padded (at least one byte) so the next one starts
+	// on an 8 byte boundary, same as upstream git.
+	pad := 8 - nameEnd%8
+	if pad == 0 {
+		pad = 8
+	}
+
+	return e, nameEnd + pad, nil
+}
+
+// Write serializes idx to path in the v2 binary format described in
+// the package doc comment, sizing each entry's hash field and the
+// trailing checksum per format (the repo's object format).
+func (idx *Index) Write(path string, format Format) error {
+	var buf bytes.Buffer
+
+	hdr := make([]byte, 12)
+	copy(hdr[0:4], signature)
+	binary.BigEndian.PutUint32(hdr[4:8], version)
+	binary.BigEndian.PutUint32(hdr[8:12], uint32(len(idx.Entries)))
+	buf.Write(hdr)
+
+	sorted := make([]Entry, len(idx.Entries))
+	copy(sorted, idx.Entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	for _, e := range sorted {
+		if err := writeEntry(&buf, e, format); err != nil {
+			return err
+		}
+	}
+
+	sum := format.NewHash()
+	sum.Write(buf.Bytes())
+	buf.Write(sum.Sum(nil))
+
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func writeEntry(w io.Writer, e Entry, format Format) error {
+	base := entryBaseSize(format)
+	b := make([]byte, base)
+	binary.BigEndian.PutUint32(b[0:4], e.CTimeSec)
+	binary.BigEndian.PutUint32(b[4:8], e.CTimeNano)
+	binary.BigEndian.PutUint32(b[8:12], e.MTimeSec)
+	binary.BigEndian.PutUint32(b[12:16], e.MTimeNano)
+	binary.BigEndian.PutUint32(b[16:20], e.Dev)
+	binary.BigEndian.PutUint32(b[20:24], e.Ino)
+	binary.BigEndian.PutUint32(b[24:28], e.Mode)
+	binary.BigEndian.PutUint32(b[28:32], e.UID)
+	binary.BigEndian.PutUint32(b[32:36], e.GID)
+	binary.BigEndian.PutUint32(b[36:40], e.Size)
+
+	hashBytes, err := hex.DecodeString(e.Hash)
+	if err != nil {
+		return fmt.Errorf("index: bad hash %q: %w", e.Hash, err)
+	}
+	if len(hashBytes) != format.HashSize {
+		return fmt.Errorf("index: hash %q is %d bytes, want %d for this object format",
+			e.Hash, len(hashBytes), format.HashSize)
+	}
+	copy(b[40:40+format.HashSize], hashBytes)
+
+	nameLen := len(e.Path)
+	flags := uint16(nameLen)
+	if flags > 0x0fff {
+		flags = 0x0fff
+	}
+	binary.BigEndian.PutUint16(b[base-2:base], flags)
+
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(e.Path)); err != nil {
+		return err
+	}
+
+	pad := 8 - (base+nameLen)%8
+	if pad == 0 {
+		pad = 8
+	}
+	_, err = w.Write(make([]byte, pad))
+	return err
+}
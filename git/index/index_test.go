@@ -0,0 +1,81 @@
+package index
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func assertEqual(t *testing.T, got, want interface{}) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestIndexRoundTrip(t *testing.T) {
+	cases := []struct {
+		name       string
+		format     Format
+		nestedHash string
+	}{
+		{"sha1", SHA1Format, "30ebf1865c4e40659bcf6bf04cb5b1a6b8e26bd9"},
+		{"sha256", SHA256Format, hexHash(SHA256Format, 2)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir(os.TempDir(), "testGoGitIndex")
+			assertEqual(t, err, nil)
+			defer os.RemoveAll(dir)
+
+			idx := New()
+			idx.Add(Entry{Mode: 0100644, Size: 12, Hash: hexHash(c.format, 1), Path: "testfile"})
+			idx.Add(Entry{Mode: 0100644, Size: 4, Hash: c.nestedHash, Path: "sub/nested.txt"})
+
+			path := filepath.Join(dir, "index")
+			assertEqual(t, idx.Write(path, c.format), nil)
+
+			got, err := Read(path, c.format)
+			assertEqual(t, err, nil)
+			assertEqual(t, len(got.Entries), 2)
+
+			e, ok := got.Get("sub/nested.txt")
+			assertEqual(t, ok, true)
+			assertEqual(t, e.Hash, c.nestedHash)
+			assertEqual(t, e.Size, uint32(4))
+
+			// A missing index file behaves like an empty one.
+			empty, err := Read(filepath.Join(dir, "does-not-exist"), c.format)
+			assertEqual(t, err, nil)
+			assertEqual(t, len(empty.Entries), 0)
+		})
+	}
+}
+
+// hexHash returns an all-zero hash of the right length for format, with
+// a single distinguishing byte at the end, so entries within one index
+// don't collide in these tests.
+func hexHash(format Format, tag byte) string {
+	b := make([]byte, format.HashSize)
+	b[len(b)-1] = tag
+	return fmt.Sprintf("%x", b)
+}
+
+func TestIndexWriteRejectsMismatchedHashSize(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "testGoGitIndex")
+	assertEqual(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	idx := New()
+	// A SHA1-sized hash string written with the SHA256 format must be
+	// rejected rather than silently truncated/zero-padded.
+	idx.Add(Entry{Mode: 0100644, Hash: "557db03de997c86a4a028e1ebd3a1ceb225be238", Path: "testfile"})
+
+	err = idx.Write(filepath.Join(dir, "index"), SHA256Format)
+	if err == nil {
+		t.Fatalf("expected an error writing a SHA1-sized hash with SHA256Format")
+	}
+}
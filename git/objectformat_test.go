@@ -0,0 +1,120 @@
+package git
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestObjectFormatHashing(t *testing.T) {
+	data := []byte("Hello World\n")
+	header := fmt.Sprintf("blob %d\x00", len(data))
+
+	cases := []struct {
+		format ObjectFormat
+		name   string
+		want   string
+	}{
+		{SHA1, "sha1", "557db03de997c86a4a028e1ebd3a1ceb225be238"},
+		{SHA256, "sha256", "7c5c8610459154bdde4984be72c48fb5d9c1c4ac793a6b5976fe38fd1b0b1284"},
+	}
+
+	for _, c := range cases {
+		assertEqual(t, c.format.String(), c.name)
+
+		h := c.format.NewHasher()
+		h.Write([]byte(header))
+		h.Write(data)
+		assertEqual(t, fmt.Sprintf("%x", h.Sum(nil)), c.want)
+	}
+
+	assertEqual(t, SHA1.HashSize(), sha1.Size)
+	assertEqual(t, SHA256.HashSize(), sha256.Size)
+	assertEqual(t, SHA1.HexSize(), sha1.Size*2)
+	assertEqual(t, SHA256.HexSize(), sha256.Size*2)
+}
+
+func TestParseObjectFormat(t *testing.T) {
+	got, err := ParseObjectFormat("sha256")
+	assertEqual(t, err, nil)
+	assertEqual(t, got, SHA256)
+
+	got, err = ParseObjectFormat("")
+	assertEqual(t, err, nil)
+	assertEqual(t, got, SHA1)
+
+	_, err = ParseObjectFormat("md5")
+	if err == nil {
+		t.Fatalf("expected an error for an unsupported object format")
+	}
+}
+
+// TestSHA256RepoRoundTrip creates a repo the way "gogit init
+// --object-format=sha256" does (NewRepo followed by
+// WriteObjectFormatExtension) and drives a blob, a tree and a commit
+// through it end to end, checking the same kind of golden hashes
+// setupTestArtifacts checks for a SHA1 repo in repo_test.go.
+// TestObjectFormatHashing alone only exercises the hasher in isolation
+// and wouldn't have caught the index entry format still being
+// hardcoded to 20 byte SHA1 hashes.
+func TestSHA256RepoRoundTrip(t *testing.T) {
+	repoDir, err := ioutil.TempDir(os.TempDir(), "testGoGitSHA256")
+	assertEqual(t, err, nil)
+	defer os.RemoveAll(repoDir)
+
+	repo, err := NewRepo(repoDir)
+	assertEqual(t, err, nil)
+	assertEqual(t, repo.WriteObjectFormatExtension(SHA256), nil)
+
+	format, err := repo.ObjectFormat()
+	assertEqual(t, err, nil)
+	assertEqual(t, format, SHA256)
+
+	testFile := "testfile"
+	testData := "Hello World\n"
+	testPath := filepath.Join(repoDir, testFile)
+	assertEqual(t, ioutil.WriteFile(testPath, []byte(testData), 0644), nil)
+
+	blob, err := NewBlobFromFile(repo, testPath)
+	assertEqual(t, err, nil)
+	blobHash, err := repo.ObjectWrite(blob.Object, true)
+	assertEqual(t, err, nil)
+	assertEqual(t, blobHash, "7c5c8610459154bdde4984be72c48fb5d9c1c4ac793a6b5976fe38fd1b0b1284")
+	assertEqual(t, len(blobHash), SHA256.HexSize())
+
+	treeInput := fmt.Sprintf("100644 blob %s\t%s\n", blobHash, testFile)
+	tree, err := NewTreeFromInput(repo, treeInput)
+	assertEqual(t, err, nil)
+	treeHash, err := repo.ObjectWrite(tree.Object, true)
+	assertEqual(t, err, nil)
+	assertEqual(t, treeHash, "f01312aa77875c10604abcff0372f375610cd919c3695429cde2992480849c0f")
+
+	commit, err := NewCommitFromParams(repo, treeHash, "", "SHA256 test commit")
+	assertEqual(t, err, nil)
+	commitHash, err := repo.ObjectWrite(commit.Object, true)
+	assertEqual(t, err, nil)
+	assertEqual(t, len(commitHash), SHA256.HexSize())
+
+	masterFile, err := repo.FilePath(false, "refs", "heads", "master")
+	assertEqual(t, err, nil)
+	assertEqual(t, ioutil.WriteFile(masterFile, []byte(commitHash+"\n"), 0644), nil)
+
+	// Short-hash resolution must work against the longer SHA256 hex too.
+	gotHash, err := repo.UniqueNameResolve(commitHash[:8])
+	assertEqual(t, err, nil)
+	assertEqual(t, gotHash, commitHash)
+
+	// Staging this file must round-trip through the index without
+	// truncating its 32 byte hash, the bug parameterizing the index
+	// format on ObjectFormat fixes.
+	assertEqual(t, repo.Add(testPath), nil)
+	idx, err := repo.Index()
+	assertEqual(t, err, nil)
+	entry, ok := idx.Get(testFile)
+	assertEqual(t, ok, true)
+	assertEqual(t, entry.Hash, blobHash)
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// OpenPGPSigner signs commits with an armored PGP private key,
+// implementing the Signer interface.
+type OpenPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewOpenPGPSigner loads the first entity out of an armored PGP
+// keyring (as produced by "gpg --export-secret-keys --armor"),
+// decrypting its private key with passphrase if it is encrypted.
+func NewOpenPGPSigner(armoredKey []byte, passphrase string) (*OpenPGPSigner, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, fmt.Errorf("git: could not read PGP key: %w", err)
+	}
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("git: no PGP keys found in the given keyring")
+	}
+
+	entity := keyring[0]
+	if entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("git: could not decrypt PGP private key: %w", err)
+		}
+	}
+
+	return &OpenPGPSigner{entity: entity}, nil
+}
+
+// Format identifies this Signer as producing "openpgp" signatures.
+func (s *OpenPGPSigner) Format() string {
+	return "openpgp"
+}
+
+// Sign returns an ASCII-armored detached signature over data.
+func (s *OpenPGPSigner) Sign(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, bytes.NewReader(data), nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// OpenPGPVerifier checks commit signatures against an armored PGP
+// keyring, implementing the Verifier interface.
+type OpenPGPVerifier struct {
+	keyring openpgp.EntityList
+}
+
+// NewOpenPGPVerifier loads an armored PGP keyring (public keys only)
+// to verify signatures against.
+func NewOpenPGPVerifier(armoredKeyring []byte) (*OpenPGPVerifier, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKeyring))
+	if err != nil {
+		return nil, fmt.Errorf("git: could not read PGP keyring: %w", err)
+	}
+	return &OpenPGPVerifier{keyring: keyring}, nil
+}
+
+// Format identifies this Verifier as checking "openpgp" signatures.
+func (v *OpenPGPVerifier) Format() string {
+	return "openpgp"
+}
+
+// Verify checks sig, an ASCII-armored detached signature, against data.
+func (v *OpenPGPVerifier) Verify(data, sig []byte) error {
+	_, err := openpgp.CheckArmoredDetachedSignature(v.keyring, bytes.NewReader(data), bytes.NewReader(sig))
+	return err
+}
@@ -0,0 +1,212 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ssrathi/gogit/git/packfile"
+)
+
+// packEntry is a single object gathered from the repo's loose object
+// store, ready to be handed to packfile.WritePack.
+type packEntry = packfile.ObjectEntry
+
+// openPack pairs an open packfile with its parsed idx, as found under
+// ".git/objects/pack".
+type openPack struct {
+	path string
+	file *os.File
+	idx  *packfile.Index
+}
+
+// packs lists every pack-*.idx/pack-*.pack pair under the repo's
+// objects/pack directory. Repos with no packs (the common case until
+// "gogit pack-objects" is run) simply have no entries here.
+func (repo *Repo) packs() ([]*openPack, error) {
+	packDir, err := repo.FilePath(false, "objects", "pack")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(packDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(packDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var packs []*openPack
+	for _, fi := range files {
+		if !strings.HasSuffix(fi.Name(), ".idx") {
+			continue
+		}
+		base := strings.TrimSuffix(fi.Name(), ".idx")
+
+		idxFile, err := os.Open(filepath.Join(packDir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		idx, err := packfile.ReadIndex(idxFile, format.packFormat())
+		idxFile.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		packPath := filepath.Join(packDir, base+".pack")
+		packFile, err := os.Open(packPath)
+		if err != nil {
+			return nil, err
+		}
+
+		packs = append(packs, &openPack{path: packPath, file: packFile, idx: idx})
+	}
+
+	return packs, nil
+}
+
+// objectFromPack looks for hash across every pack in the repo. It is
+// consulted by ObjectParse as a fallback once the loose object lookup
+// (".git/objects/xx/yyy...") misses, so lookups pay the cost of
+// scanning packs only for objects that were actually packed.
+func (repo *Repo) objectFromPack(hash string) (*GitObject, error) {
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	packs, err := repo.packs()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, p := range packs {
+			p.file.Close()
+		}
+	}()
+
+	for _, p := range packs {
+		offset, ok := p.idx.FindOffset(hash)
+		if !ok {
+			continue
+		}
+
+		objType, data, err := packfile.ReadObjectAt(p.file, offset, repo.resolveRefDeltaBase, format.packFormat())
+		if err != nil {
+			return nil, err
+		}
+		return NewObject(objType, data), nil
+	}
+
+	return nil, fmt.Errorf("Object %q not found in any pack", hash)
+}
+
+// resolveRefDeltaBase resolves an OBJ_REF_DELTA base by hash, used by
+// packfile.ReadObjectAt for packs (not produced by gogit itself, which
+// only writes OBJ_OFS_DELTA) that delta against an object outside the
+// pack being read.
+func (repo *Repo) resolveRefDeltaBase(hash string) (string, []byte, error) {
+	obj, err := repo.ObjectParse(hash)
+	if err != nil {
+		return "", nil, err
+	}
+	return obj.ObjType, obj.ObjData, nil
+}
+
+// WritePackObjects writes objects into a new pack, named after the
+// packfile's own trailing SHA1 as "pack-<sha>.pack"/".idx" under
+// ".git/objects/pack", and returns that SHA1 as a hex string.
+func (repo *Repo) WritePackObjects(objects []packEntry) (string, error) {
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return "", err
+	}
+
+	packDir, err := repo.FilePath(true, "objects", "pack")
+	if err != nil {
+		return "", err
+	}
+
+	packPath := filepath.Join(packDir, "pack-incoming.pack")
+	packFile, err := os.OpenFile(packPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	packSHA, entries, err := packfile.WritePack(packFile, objects, format.packFormat())
+	packFile.Close()
+	if err != nil {
+		return "", err
+	}
+
+	packSHAHex := fmt.Sprintf("%x", packSHA)
+	finalPackPath := filepath.Join(packDir, "pack-"+packSHAHex+".pack")
+	if err := os.Rename(packPath, finalPackPath); err != nil {
+		return "", err
+	}
+
+	idxPath := filepath.Join(packDir, "pack-"+packSHAHex+".idx")
+	idxFile, err := os.OpenFile(idxPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer idxFile.Close()
+
+	if err := packfile.WriteIndex(idxFile, entries, packSHA, format.packFormat()); err != nil {
+		return "", err
+	}
+
+	return packSHAHex, nil
+}
+
+// UnpackObjects explodes every object in a pack back out to loose
+// objects under ".git/objects/xx/yyy...", the inverse of
+// WritePackObjects. It is used by "gogit unpack-objects".
+func (repo *Repo) UnpackObjects(packPath string) (int, error) {
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return 0, err
+	}
+
+	packFile, err := os.Open(packPath)
+	if err != nil {
+		return 0, err
+	}
+	defer packFile.Close()
+
+	idxPath := strings.TrimSuffix(packPath, ".pack") + ".idx"
+	idxFile, err := os.Open(idxPath)
+	if err != nil {
+		return 0, err
+	}
+	idx, err := packfile.ReadIndex(idxFile, format.packFormat())
+	idxFile.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, hash := range idx.Hashes() {
+		offset, _ := idx.FindOffset(hash)
+		objType, data, err := packfile.ReadObjectAt(packFile, offset, repo.resolveRefDeltaBase, format.packFormat())
+		if err != nil {
+			return count, err
+		}
+
+		obj := NewObject(objType, data)
+		if _, err := repo.ObjectWrite(obj, true); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,287 @@
+package packfile
+
+// This file implements delta encoding/decoding between two object
+// blobs, following the same copy/insert instruction stream git itself
+// uses inside a packfile.
+//
+// Matching candidate runs between base and target is done with a
+// rolling-hash index of the base: the base is split into fixed size
+// blocks, each block is hashed with a cheap Adler-32 style rolling
+// checksum, and each block of the target is hashed the same way to look
+// up candidate offsets in the base. Matches are then extended
+// byte-by-byte in both directions.
+
+// blockSize is the size of the blocks the base object is indexed in.
+const blockSize = 16
+
+// maxInsertRun is the largest number of literal bytes a single insert
+// instruction can carry (flag byte 0x01-0x7f holds the run length).
+const maxInsertRun = 0x7f
+
+// maxCopySize is the largest byte count a single copy instruction can
+// carry; 0 in the encoded size field means exactly this many bytes.
+const maxCopySize = 0x10000
+
+// deltaIndex is a rolling-hash index of a base object, mapping each
+// block's checksum to every offset in base it was seen at (a simple
+// chained hashmap to absorb collisions).
+type deltaIndex struct {
+	base  []byte
+	table map[uint32][]int
+}
+
+// newDeltaIndex builds a deltaIndex over base by hashing every
+// non-overlapping blockSize-byte block.
+func newDeltaIndex(base []byte) *deltaIndex {
+	di := &deltaIndex{base: base, table: map[uint32][]int{}}
+	for off := 0; off+blockSize <= len(base); off += blockSize {
+		sum := adlerBlock(base[off : off+blockSize])
+		di.table[sum] = append(di.table[sum], off)
+	}
+	return di
+}
+
+// adlerBlock computes a simple Fletcher/Adler-style rolling checksum of
+// a fixed-size block.
+func adlerBlock(block []byte) uint32 {
+	var a, b uint32 = 1, 0
+	for _, c := range block {
+		a += uint32(c)
+		b += a
+	}
+	return a | (b << 16)
+}
+
+// match is a single run of bytes in target that exist verbatim
+// somewhere in base.
+type match struct {
+	baseOff, targetOff, length int
+}
+
+// findMatch looks up the block starting at target[off:] in the index
+// and, on a hit, greedily extends the match in both directions. It
+// returns ok=false if no block-aligned hit was found.
+func (di *deltaIndex) findMatch(target []byte, off int) (match, bool) {
+	if off+blockSize > len(target) {
+		return match{}, false
+	}
+
+	sum := adlerBlock(target[off : off+blockSize])
+	var best match
+	for _, baseOff := range di.table[sum] {
+		// Confirm the hash isn't a collision before trusting it.
+		if !bytesEqual(di.base[baseOff:baseOff+blockSize], target[off:off+blockSize]) {
+			continue
+		}
+
+		start, tStart := baseOff, off
+		for start > 0 && tStart > 0 && di.base[start-1] == target[tStart-1] {
+			start--
+			tStart--
+		}
+
+		end, tEnd := baseOff+blockSize, off+blockSize
+		for end < len(di.base) && tEnd < len(target) && di.base[end] == target[tEnd] {
+			end++
+			tEnd++
+		}
+
+		if end-start > best.length {
+			best = match{baseOff: start, targetOff: tStart, length: end - start}
+		}
+	}
+
+	return best, best.length > 0
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeDelta produces a git-format delta turning base into target: a
+// pair of size varints (base size, target size) followed by a sequence
+// of copy ops (0x80|flags, base offset + size) for runs that exist in
+// base and insert ops (0x01-0x7f, literal bytes) for everything else.
+func encodeDelta(base, target []byte) []byte {
+	out := make([]byte, 0, len(target)/2+32)
+	out = appendDeltaSizeVarint(out, len(base))
+	out = appendDeltaSizeVarint(out, len(target))
+
+	di := newDeltaIndex(base)
+
+	var literal []byte
+	flush := func() {
+		for len(literal) > 0 {
+			n := len(literal)
+			if n > maxInsertRun {
+				n = maxInsertRun
+			}
+			out = append(out, byte(n))
+			out = append(out, literal[:n]...)
+			literal = literal[n:]
+		}
+	}
+
+	i := 0
+	for i < len(target) {
+		m, ok := di.findMatch(target, i)
+		if !ok || m.length < blockSize {
+			literal = append(literal, target[i])
+			i++
+			continue
+		}
+
+		// A match extended backwards past i covers target bytes that are
+		// already in literal (or a previous copy); skip that part of it.
+		if m.targetOff < i {
+			skip := i - m.targetOff
+			m.baseOff += skip
+			m.targetOff += skip
+			m.length -= skip
+		}
+
+		flush()
+		off, length := m.baseOff, m.length
+		i = m.targetOff + m.length
+		for length > 0 {
+			n := length
+			if n > maxCopySize {
+				n = maxCopySize
+			}
+			out = append(out, encodeCopyOp(off, n)...)
+			off += n
+			length -= n
+		}
+	}
+	flush()
+
+	return out
+}
+
+// appendDeltaSizeVarint appends the object-size varint (7 bits per
+// byte, low-order first, continuation in the high bit) used for the
+// base/target size header of a delta.
+func appendDeltaSizeVarint(out []byte, size int) []byte {
+	for {
+		b := byte(size & 0x7f)
+		size >>= 7
+		if size != 0 {
+			out = append(out, b|0x80)
+		} else {
+			out = append(out, b)
+			return out
+		}
+	}
+}
+
+// encodeCopyOp encodes a single copy instruction: a command byte with
+// bit 0x80 set, followed by whichever offset/size bytes are non-zero
+// (flagged in the low 7 bits of the command byte).
+func encodeCopyOp(offset, size int) []byte {
+	var offBytes, sizeBytes [4]byte
+	offBytes[0] = byte(offset)
+	offBytes[1] = byte(offset >> 8)
+	offBytes[2] = byte(offset >> 16)
+	offBytes[3] = byte(offset >> 24)
+	sizeBytes[0] = byte(size)
+	sizeBytes[1] = byte(size >> 8)
+	sizeBytes[2] = byte(size >> 16)
+	// A size of exactly maxCopySize is encoded as 0 per the copy-op spec.
+	if size == maxCopySize {
+		sizeBytes[0], sizeBytes[1], sizeBytes[2] = 0, 0, 0
+	}
+
+	cmd := byte(0x80)
+	op := []byte{0}
+	for i, b := range offBytes {
+		if b != 0 {
+			cmd |= 1 << uint(i)
+			op = append(op, b)
+		}
+	}
+	for i, b := range sizeBytes {
+		if b != 0 {
+			cmd |= 1 << uint(4+i)
+			op = append(op, b)
+		}
+	}
+	op[0] = cmd
+	return op
+}
+
+// decodeDelta applies a delta produced by encodeDelta (or by upstream
+// git) to base, returning the reconstructed target.
+func decodeDelta(base, delta []byte) ([]byte, error) {
+	baseSize, n := readDeltaSizeVarint(delta)
+	delta = delta[n:]
+	if baseSize != len(base) {
+		return nil, errDeltaBaseSize
+	}
+	targetSize, n := readDeltaSizeVarint(delta)
+	delta = delta[n:]
+
+	out := make([]byte, 0, targetSize)
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+
+		if cmd&0x80 != 0 {
+			var offset, size int
+			for i := uint(0); i < 4; i++ {
+				if cmd&(1<<i) != 0 {
+					offset |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := uint(0); i < 3; i++ {
+				if cmd&(1<<(4+i)) != 0 {
+					size |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if size == 0 {
+				size = maxCopySize
+			}
+			if offset+size > len(base) {
+				return nil, errDeltaCopyRange
+			}
+			out = append(out, base[offset:offset+size]...)
+		} else if cmd != 0 {
+			n := int(cmd)
+			if n > len(delta) {
+				return nil, errDeltaInsertRange
+			}
+			out = append(out, delta[:n]...)
+			delta = delta[n:]
+		} else {
+			return nil, errDeltaReservedOp
+		}
+	}
+
+	if len(out) != targetSize {
+		return nil, errDeltaTargetSize
+	}
+	return out, nil
+}
+
+func readDeltaSizeVarint(b []byte) (int, int) {
+	size, shift, i := 0, uint(0), 0
+	for {
+		c := b[i]
+		size |= int(c&0x7f) << shift
+		i++
+		if c&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	return size, i
+}
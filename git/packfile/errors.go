@@ -0,0 +1,11 @@
+package packfile
+
+import "errors"
+
+var (
+	errDeltaBaseSize    = errors.New("packfile: delta base size mismatch")
+	errDeltaCopyRange   = errors.New("packfile: delta copy op out of range")
+	errDeltaInsertRange = errors.New("packfile: delta insert op out of range")
+	errDeltaReservedOp  = errors.New("packfile: reserved delta opcode 0x00")
+	errDeltaTargetSize  = errors.New("packfile: delta result size mismatch")
+)
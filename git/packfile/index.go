@@ -0,0 +1,259 @@
+package packfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+)
+
+// idxSignature is the 4 byte magic that marks a version 2 (or later)
+// idx file; version 1 idx files have no magic at all.
+const idxSignature = 0xff744f63
+
+// idxVersion is the only idx version gogit writes.
+const idxVersion = 2
+
+// Index is the parsed form of a ".idx" file: for every object in the
+// matching pack, its SHA1, CRC32 of the compressed pack data, and
+// offset into the packfile.
+type Index struct {
+	entries map[string]PackedEntry
+}
+
+// WriteIndex writes a version 2 idx file describing entries, which may
+// be in any order, e.g. the pack order WritePack returns them in
+// (WriteIndex sorts its own copy by hash, as the format requires). format's hash
+// determines both the size of the per-object hash entries and the
+// algorithm used for the idx's own trailing checksum; a SHA256Format
+// idx is therefore only readable by a gogit build that also knows the
+// object hashes involved are 32 bytes, same as the SHA256 extension.
+//
+// The layout is: signature, version, a 256-entry fanout table, then for
+// every object in hash order: the hash, then (in three separate
+// tables) the CRC32s, the 4-byte offsets (with the top bit set and an
+// index into an 8-byte overflow table for offsets >= 2^31), the
+// overflow table, and finally the pack's trailing checksum and the
+// checksum of everything written so far.
+func WriteIndex(w io.Writer, entries []PackedEntry, packSHA []byte, format Format) error {
+	sorted := make([]PackedEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Hash < sorted[j].Hash })
+
+	h := format.NewHash()
+	cw := &countingHashWriter{w: w, h: h}
+
+	hdr := make([]byte, 8)
+	binary.BigEndian.PutUint32(hdr[0:4], idxSignature)
+	binary.BigEndian.PutUint32(hdr[4:8], idxVersion)
+	if _, err := cw.Write(hdr); err != nil {
+		return err
+	}
+
+	var fanout [256]uint32
+	for _, e := range sorted {
+		firstByte := hashFirstByte(e.Hash)
+		for i := int(firstByte); i < 256; i++ {
+			fanout[i]++
+		}
+	}
+	for _, count := range fanout {
+		if err := writeUint32(cw, count); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range sorted {
+		raw, err := hex.DecodeString(e.Hash)
+		if err != nil {
+			return fmt.Errorf("packfile: bad hash %q: %w", e.Hash, err)
+		}
+		if _, err := cw.Write(raw); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range sorted {
+		if err := writeUint32(cw, e.CRC32); err != nil {
+			return err
+		}
+	}
+
+	var overflow []int64
+	for _, e := range sorted {
+		if e.Offset < 1<<31 {
+			if err := writeUint32(cw, uint32(e.Offset)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeUint32(cw, 0x80000000|uint32(len(overflow))); err != nil {
+			return err
+		}
+		overflow = append(overflow, e.Offset)
+	}
+	for _, off := range overflow {
+		if err := writeUint64(cw, uint64(off)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := cw.Write(packSHA); err != nil {
+		return err
+	}
+
+	_, err := w.Write(h.Sum(nil))
+	return err
+}
+
+func hashFirstByte(hexHash string) byte {
+	b, _ := hex.DecodeString(hexHash[0:2])
+	return b[0]
+}
+
+func writeUint32(w io.Writer, v uint32) error {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	_, err := w.Write(b[:])
+	return err
+}
+
+func writeUint64(w io.Writer, v uint64) error {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	_, err := w.Write(b[:])
+	return err
+}
+
+// ReadIndex parses a version 2 idx file written with format's hash
+// size, returning its object -> (offset, CRC32) table.
+func ReadIndex(r io.Reader, format Format) (*Index, error) {
+	br := bufio.NewReader(r)
+
+	hdr := make([]byte, 8)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(hdr[0:4]) != idxSignature {
+		return nil, fmt.Errorf("packfile: not a version 2 idx file")
+	}
+	if v := binary.BigEndian.Uint32(hdr[4:8]); v != idxVersion {
+		return nil, fmt.Errorf("packfile: unsupported idx version %d", v)
+	}
+
+	var fanout [256]uint32
+	for i := range fanout {
+		v, err := readUint32(br)
+		if err != nil {
+			return nil, err
+		}
+		fanout[i] = v
+	}
+	count := int(fanout[255])
+
+	hashes := make([]string, count)
+	for i := 0; i < count; i++ {
+		raw := make([]byte, format.HashSize)
+		if _, err := io.ReadFull(br, raw); err != nil {
+			return nil, err
+		}
+		hashes[i] = hex.EncodeToString(raw)
+	}
+
+	crcs := make([]uint32, count)
+	for i := 0; i < count; i++ {
+		v, err := readUint32(br)
+		if err != nil {
+			return nil, err
+		}
+		crcs[i] = v
+	}
+
+	rawOffsets := make([]uint32, count)
+	overflowCount := 0
+	for i := 0; i < count; i++ {
+		v, err := readUint32(br)
+		if err != nil {
+			return nil, err
+		}
+		rawOffsets[i] = v
+		if v&0x80000000 != 0 {
+			overflowCount++
+		}
+	}
+
+	overflow := make([]int64, overflowCount)
+	for i := range overflow {
+		v, err := readUint64(br)
+		if err != nil {
+			return nil, err
+		}
+		overflow[i] = int64(v)
+	}
+
+	idx := &Index{entries: make(map[string]PackedEntry, count)}
+	for i, h := range hashes {
+		offset := int64(rawOffsets[i])
+		if rawOffsets[i]&0x80000000 != 0 {
+			offset = overflow[rawOffsets[i]&0x7fffffff]
+		}
+		idx.entries[h] = PackedEntry{Hash: h, CRC32: crcs[i], Offset: offset}
+	}
+
+	return idx, nil
+}
+
+// FindOffset returns the pack offset of hash, and whether it was found.
+func (idx *Index) FindOffset(hash string) (int64, bool) {
+	e, ok := idx.entries[hash]
+	return e.Offset, ok
+}
+
+// Hashes returns every object hash this index knows about.
+func (idx *Index) Hashes() []string {
+	hashes := make([]string, 0, len(idx.entries))
+	for h := range idx.entries {
+		hashes = append(hashes, h)
+	}
+	return hashes
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+func readUint64(r io.Reader) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// crcWriter is an io.Writer that feeds everything written through it to
+// an underlying writer while accumulating a CRC32 (IEEE) checksum.
+type crcWriter struct {
+	w   io.Writer
+	crc uint32
+}
+
+func newCRCWriter(w io.Writer) *crcWriter {
+	return &crcWriter{w: w}
+}
+
+func (c *crcWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.crc = crc32.Update(c.crc, crc32.IEEETable, p[:n])
+	return n, err
+}
+
+func (c *crcWriter) Sum32() uint32 {
+	return c.crc
+}
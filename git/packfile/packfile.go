@@ -0,0 +1,282 @@
+/*
+Package packfile implements reading and writing of the standard Git
+packfile format (".pack" + ".idx" v2).
+
+A packfile stores a sequence of objects, each either undeltified (the
+full zlib-deflated object data, same as a loose object) or delta
+encoded against a "base" object that appears earlier in the same
+packfile. Delta selection uses a sliding window over the candidate
+objects (see findDeltaBase); delta.go holds the delta encoding itself.
+*/
+package packfile
+
+import (
+	"compress/zlib"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Signature is the 4 byte magic that begins every packfile.
+const Signature = "PACK"
+
+// Version is the only packfile version gogit knows how to produce.
+const Version = 2
+
+// DefaultWindow is the number of preceding candidate objects considered
+// as a delta base for each object written to a pack.
+const DefaultWindow = 10
+
+// MaxDeltaRatio is the largest fraction (delta size / undeltified size)
+// that gogit is willing to accept before giving up and storing an
+// object undeltified.
+const MaxDeltaRatio = 0.50
+
+// Object type values as stored in a pack object header. These match the
+// upstream git values so packs written by gogit are byte compatible.
+const (
+	ObjCommit   = 1
+	ObjTree     = 2
+	ObjBlob     = 3
+	ObjTag      = 4
+	ObjOfsDelta = 6
+	ObjRefDelta = 7
+)
+
+var typeNames = map[string]int{
+	"commit": ObjCommit,
+	"tree":   ObjTree,
+	"blob":   ObjBlob,
+	"tag":    ObjTag,
+}
+
+// ObjectEntry is a single object to be stored in a packfile. Path is an
+// optional hint (the tree entry name the object was last seen under)
+// used to steer delta base selection towards similarly named objects.
+type ObjectEntry struct {
+	Hash string
+	Type string
+	Data []byte
+	Path string
+}
+
+// PackedEntry describes where an object ended up inside a freshly
+// written packfile, used to build the accompanying .idx file.
+type PackedEntry struct {
+	Hash   string
+	CRC32  uint32
+	Offset int64
+}
+
+// Format describes the hash algorithm a pack's checksums (the trailing
+// packfile checksum, and every hash in its idx) are computed with. The
+// packfile package has no notion of "git object format" of its own, so
+// callers (the git package) hand in the Format matching the repo's
+// extensions.objectFormat.
+type Format struct {
+	HashSize int
+	NewHash  func() hash.Hash
+}
+
+// SHA1Format is the format every gogit repo used before SHA256 support
+// was added, and remains the default.
+var SHA1Format = Format{HashSize: sha1.Size, NewHash: sha1.New}
+
+// SHA256Format is used by repos initialized with
+// "gogit init --object-format=sha256".
+var SHA256Format = Format{HashSize: sha256.Size, NewHash: sha256.New}
+
+// WritePack writes objects to w in pack order, returning the packfile's
+// trailing checksum (over everything written before it, using
+// format's hash) along with the offset/CRC32 of every object, ready to
+// be handed to WriteIndex.
+//
+// Objects are encoded in the order given. For each object, the best
+// delta base among the preceding DefaultWindow objects of the same type
+// is picked (see findDeltaBase); if no base shrinks the object by more
+// than MaxDeltaRatio, it is stored undeltified.
+func WritePack(w io.Writer, objects []ObjectEntry, format Format) ([]byte, []PackedEntry, error) {
+	h := format.NewHash()
+	cw := &countingHashWriter{w: w, h: h}
+
+	hdr := make([]byte, 12)
+	copy(hdr[0:4], Signature)
+	binary.BigEndian.PutUint32(hdr[4:8], Version)
+	binary.BigEndian.PutUint32(hdr[8:12], uint32(len(objects)))
+	if _, err := cw.Write(hdr); err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]PackedEntry, len(objects))
+	for i, obj := range objects {
+		base, baseOffset := findDeltaBase(objects, i, entries)
+
+		var payload []byte
+		objType := obj.Type
+		if base != nil {
+			delta := encodeDelta(base.Data, obj.Data)
+			if len(delta) < int(float64(len(obj.Data))*MaxDeltaRatio) {
+				payload = delta
+				objType = "ofs-delta"
+			}
+		}
+		if payload == nil {
+			payload = obj.Data
+		}
+
+		offset := cw.written
+		crc, err := writePackObject(cw, objType, payload, offset-baseOffset)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries[i] = PackedEntry{Hash: obj.Hash, CRC32: crc, Offset: offset}
+	}
+
+	return h.Sum(nil), entries, nil
+}
+
+// findDeltaBase walks up to DefaultWindow previously written objects of
+// the same type looking for the best delta base for objects[i]. It
+// returns the candidate and its pack offset, or (nil, 0) if none of the
+// window's objects produced a worthwhile delta.
+func findDeltaBase(objects []ObjectEntry, i int, entries []PackedEntry) (*ObjectEntry, int64) {
+	target := objects[i]
+	var best *ObjectEntry
+	var bestOffset int64
+	bestLen := len(target.Data)
+
+	start := i - DefaultWindow
+	if start < 0 {
+		start = 0
+	}
+
+	for j := i - 1; j >= start; j-- {
+		cand := objects[j]
+		if cand.Type != target.Type {
+			continue
+		}
+		// Only consider similarly sized/named objects, same heuristic
+		// upstream git uses to keep delta search cheap.
+		if !similar(cand, target) {
+			continue
+		}
+
+		delta := encodeDelta(cand.Data, target.Data)
+		if len(delta) < bestLen {
+			bestLen = len(delta)
+			best = &objects[j]
+			bestOffset = entries[j].Offset
+		}
+	}
+
+	return best, bestOffset
+}
+
+// similar reports whether cand is a plausible delta base for target:
+// same name (if known) or a size within 2x of each other.
+func similar(cand, target ObjectEntry) bool {
+	if cand.Path != "" && target.Path != "" && cand.Path == target.Path {
+		return true
+	}
+	small, big := len(cand.Data), len(target.Data)
+	if small > big {
+		small, big = big, small
+	}
+	if small == 0 {
+		return big == 0
+	}
+	return big/small < 2
+}
+
+// writePackObject writes a single pack object header (type + size
+// varint, plus the ofs-delta base offset when objType is "ofs-delta")
+// followed by the zlib-deflated payload. It returns the CRC32 of the
+// bytes written (header + compressed payload), as stored in the idx.
+func writePackObject(cw *countingHashWriter, objType string, payload []byte, baseOffset int64) (uint32, error) {
+	crc := newCRCWriter(cw)
+
+	typeNum, ok := typeNames[objType]
+	if !ok && objType == "ofs-delta" {
+		typeNum = ObjOfsDelta
+	} else if !ok {
+		return 0, fmt.Errorf("packfile: unknown object type %q", objType)
+	}
+
+	if err := writeObjectHeader(crc, typeNum, len(payload)); err != nil {
+		return 0, err
+	}
+	if typeNum == ObjOfsDelta {
+		if err := writeOffsetVarint(crc, baseOffset); err != nil {
+			return 0, err
+		}
+	}
+
+	zw := zlib.NewWriter(crc)
+	if _, err := zw.Write(payload); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+
+	return crc.Sum32(), nil
+}
+
+// writeObjectHeader writes the git pack object header: the type is
+// stored in bits 4-6 of the first byte, the low 4 bits and every
+// following 7-bit group hold the size, little-endian, MSB-first
+// continuation.
+func writeObjectHeader(w io.Writer, objType int, size int) error {
+	first := byte(objType<<4) | byte(size&0x0f)
+	size >>= 4
+	for size != 0 {
+		first |= 0x80
+		if _, err := w.Write([]byte{first}); err != nil {
+			return err
+		}
+		first = byte(size & 0x7f)
+		size >>= 7
+	}
+	_, err := w.Write([]byte{first})
+	return err
+}
+
+// writeOffsetVarint writes a relative base offset using the (unusual)
+// big-endian, base-128 encoding git uses for OBJ_OFS_DELTA: each byte
+// but the last has its continuation bit set, and every continued byte
+// adds 2^(7*n) rather than simply shifting, per pack-format.txt.
+func writeOffsetVarint(w io.Writer, offset int64) error {
+	var buf [10]byte
+	n := len(buf)
+	n--
+	buf[n] = byte(offset & 0x7f)
+	offset >>= 7
+	for offset != 0 {
+		offset--
+		n--
+		buf[n] = 0x80 | byte(offset&0x7f)
+		offset >>= 7
+	}
+	_, err := w.Write(buf[n:])
+	return err
+}
+
+// countingHashWriter tees everything written to both an io.Writer and a
+// running hash, while tracking the number of bytes written so far.
+type countingHashWriter struct {
+	w       io.Writer
+	h       io.Writer
+	written int64
+}
+
+func (c *countingHashWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if n > 0 {
+		c.h.Write(p[:n])
+	}
+	c.written += int64(n)
+	return n, err
+}
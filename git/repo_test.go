@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/ssrathi/gogit/git/trie"
 	"github.com/ssrathi/gogit/util"
 )
 
@@ -341,4 +342,48 @@ func TestCommands(t *testing.T) {
 		assertEqual(t, err, nil)
 		assertEqual(t, string(data), testData)
 	})
+
+	// Validate 'status' against a mix of staged, unstaged and untracked
+	// changes, driven by the merkle-trie diff.
+	t.Run("Validate status with staged, unstaged and untracked files", func(t *testing.T) {
+		testFilePath := filepath.Join(repoDir, testFile)
+
+		// Stage a change to the tracked file: shows up on the index/HEAD
+		// side of the diff.
+		err := ioutil.WriteFile(testFilePath, []byte("Staged change\n"), 0644)
+		assertEqual(t, err, nil)
+		assertEqual(t, repo.Add(testFilePath), nil)
+
+		// Change it again without staging: shows up on the work
+		// tree/index side.
+		err = ioutil.WriteFile(testFilePath, []byte("Unstaged change\n"), 0644)
+		assertEqual(t, err, nil)
+
+		// A brand new file nobody has staged: untracked.
+		untrackedFile := "untracked.txt"
+		err = ioutil.WriteFile(filepath.Join(repoDir, untrackedFile), []byte("new\n"), 0644)
+		assertEqual(t, err, nil)
+
+		changes, err := repo.Status()
+		assertEqual(t, err, nil)
+
+		var sawStaged, sawUnstaged, sawUntracked bool
+		for _, c := range changes {
+			switch {
+			case c.Side == trie.IndexHead && c.Path == testFile && c.Action == trie.Modified:
+				sawStaged = true
+			case c.Side == trie.WorkTreeIndex && c.Path == testFile && c.Action == trie.Modified:
+				sawUnstaged = true
+			case c.Side == trie.WorkTreeIndex && c.Path == untrackedFile && c.Action == trie.Added:
+				sawUntracked = true
+			}
+		}
+		assertEqual(t, sawStaged, true)
+		assertEqual(t, sawUnstaged, true)
+		assertEqual(t, sawUntracked, true)
+	})
+
+	// Validate that a commit built via NewCommitFromParamsSigned carries
+	// a working "gpgsig" header.
+	t.Run("Validate signed commit round-trip", testSignedCommit)
 }
\ No newline at end of file
@@ -0,0 +1,296 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ssrathi/gogit/git/index"
+	"github.com/ssrathi/gogit/git/trie"
+)
+
+// ResetMode selects how far "gogit reset" rewinds repo state: just the
+// current branch ref, the ref plus the index, or the ref, the index
+// and the work tree.
+type ResetMode int
+
+// The three reset modes "gogit reset" supports, matching stock git's
+// "--soft"/"--mixed"/"--hard".
+const (
+	SoftReset ResetMode = iota
+	MixedReset
+	HardReset
+)
+
+// CheckoutOptions controls how Repo.Checkout materializes a tree onto a
+// directory. Without Force, an existing file or directory at a target
+// path is left alone and checkout fails, refusing to clobber local
+// content, same as a plain Tree.Checkout into a fresh directory; Reset's
+// hard mode sets Force so matching paths are removed and overwritten in
+// place instead. Branch/Hash identify the ref/commit this checkout is
+// for; Checkout itself ignores them (Reset updates the ref separately),
+// but callers building a CheckoutOptions for logging or error messages
+// don't need to thread that context anywhere else.
+type CheckoutOptions struct {
+	Force  bool
+	Branch string
+	Hash   string
+}
+
+// Checkout materializes the tree identified by treeHash under path,
+// recreating its directories and writing its blobs. It is Tree.Checkout's
+// Force-aware counterpart, driven by a tree hash rather than an
+// already-parsed *Tree so Reset can call it without needing one, and is
+// what a hard reset uses to overwrite the work tree in place.
+func (repo *Repo) Checkout(treeHash, path string, opts CheckoutOptions) error {
+	return repo.checkoutNode(trie.NewTreeRoot(repo, treeHash), path, opts)
+}
+
+func (repo *Repo) checkoutNode(node trie.Node, path string, opts CheckoutOptions) error {
+	children, err := node.Children()
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		childPath := filepath.Join(path, child.Name())
+
+		// A directory entry that already exists as a directory is fine to
+		// descend into as-is; anything else occupying childPath (a file
+		// where the tree wants a directory, a directory where it wants a
+		// file, or a file that needs overwriting) has to be cleared first,
+		// and this has to happen before the dir/file branch below so Force
+		// is honored for directory entries too, not just blobs.
+		info, statErr := os.Stat(childPath)
+		if statErr == nil && !(child.IsDir() && info.IsDir()) {
+			if !opts.Force {
+				return fmt.Errorf("git: %q already exists, refusing to overwrite without Force", childPath)
+			}
+			if err := os.RemoveAll(childPath); err != nil {
+				return err
+			}
+		}
+
+		if child.IsDir() {
+			if err := os.MkdirAll(childPath, os.ModePerm); err != nil {
+				return err
+			}
+			if err := repo.checkoutNode(child, childPath, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		obj, err := repo.ObjectParse(child.Hash())
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(childPath, obj.ObjData, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reset moves the current branch to target (resolved the same way
+// "gogit commit -parent" resolves its parent) and, depending on mode,
+// rewrites the index and work tree to match:
+//
+//   - SoftReset only moves the branch ref.
+//   - MixedReset also repopulates the index from the target tree.
+//   - HardReset does both of those and overwrites the work tree,
+//     deleting tracked files that disappear in the target tree.
+func (repo *Repo) Reset(target string, mode ResetMode) error {
+	hash, err := repo.UniqueNameResolve(target)
+	if err != nil {
+		return err
+	}
+
+	treeHash, err := repo.commitTreeHash(hash)
+	if err != nil {
+		return err
+	}
+
+	branchRef, err := repo.currentBranchRef()
+	if err != nil {
+		return err
+	}
+	if err := repo.updateRef(branchRef, hash); err != nil {
+		return err
+	}
+
+	if mode == SoftReset {
+		return nil
+	}
+
+	workTree, err := repo.workTreeRoot()
+	if err != nil {
+		return err
+	}
+
+	if mode == HardReset {
+		if err := repo.removeGoneFiles(workTree, treeHash); err != nil {
+			return err
+		}
+		if err := repo.Checkout(treeHash, workTree, CheckoutOptions{Force: true, Branch: branchRef, Hash: hash}); err != nil {
+			return err
+		}
+	}
+
+	idx, err := repo.indexFromTree(treeHash, workTree, mode == HardReset)
+	if err != nil {
+		return err
+	}
+	return repo.WriteIndex(idx)
+}
+
+// commitTreeHash returns the tree hash recorded by the commit at hash.
+func (repo *Repo) commitTreeHash(hash string) (string, error) {
+	obj, err := repo.ObjectParse(hash)
+	if err != nil {
+		return "", err
+	}
+	commit, err := NewCommit(repo, obj)
+	if err != nil {
+		return "", err
+	}
+	return commit.TreeHash(), nil
+}
+
+// currentBranchRef reads ".git/HEAD" and returns the ref it points at
+// (e.g. "refs/heads/master"). It errors out on a detached HEAD, since
+// there is no branch ref for a caller like Reset or UpdateBranchRef to
+// move in that case.
+func (repo *Repo) currentBranchRef() (string, error) {
+	headPath, err := repo.FilePath(false, "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(headPath)
+	if err != nil {
+		return "", err
+	}
+
+	const prefix = "ref: "
+	content := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(content, prefix) {
+		return "", fmt.Errorf("git: HEAD is detached, no branch ref to move")
+	}
+	return strings.TrimPrefix(content, prefix), nil
+}
+
+// updateRef writes hash to the ref file at ref (e.g.
+// "refs/heads/master"), creating any missing parent directories.
+func (repo *Repo) updateRef(ref, hash string) error {
+	path, err := repo.FilePath(true, strings.Split(ref, "/")...)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(hash+"\n"), 0644)
+}
+
+// UpdateBranchRef moves the current branch to hash, the same ref move
+// Reset performs: "gogit commit" calls this after writing a new commit
+// object so HEAD/the branch actually advances to it, the same way a
+// real "git commit" would.
+func (repo *Repo) UpdateBranchRef(hash string) error {
+	branchRef, err := repo.currentBranchRef()
+	if err != nil {
+		return err
+	}
+	return repo.updateRef(branchRef, hash)
+}
+
+// treeBlobPaths walks the tree at hash, via the same walkTreeBlobs
+// TreeBuilderFromTree uses, returning one index.Entry per blob with
+// only Mode (always 0100644), Path and Hash set, sufficient for both comparing against the
+// current index (removeGoneFiles) and seeding stat info
+// (indexFromTree). hash may be "" for an empty tree.
+func (repo *Repo) treeBlobPaths(hash, prefix string) ([]index.Entry, error) {
+	var entries []index.Entry
+	err := walkTreeBlobs(repo, hash, prefix, func(path, blobHash string) error {
+		entries = append(entries, index.Entry{Mode: 0100644, Hash: blobHash, Path: path})
+		return nil
+	})
+	return entries, err
+}
+
+// removeGoneFiles deletes every work tree file tracked by the current
+// index whose path no longer exists in the tree at treeHash, the
+// "deleting tracked files that disappear" half of a hard reset.
+func (repo *Repo) removeGoneFiles(workTree, treeHash string) error {
+	oldIdx, err := repo.Index()
+	if err != nil {
+		return err
+	}
+
+	newEntries, err := repo.treeBlobPaths(treeHash, "")
+	if err != nil {
+		return err
+	}
+	stillPresent := make(map[string]bool, len(newEntries))
+	for _, e := range newEntries {
+		stillPresent[e.Path] = true
+	}
+
+	for _, e := range oldIdx.Entries {
+		if stillPresent[e.Path] {
+			continue
+		}
+		path := filepath.Join(workTree, e.Path)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexFromTree builds a fresh index matching the tree at treeHash.
+// When fromDisk is true (hard reset, run after Checkout has written the
+// files) each entry's stat fields come from the real file under
+// workTree; otherwise (mixed reset, which never touches the work tree)
+// they're synthesized from the blob itself, the same way HashFile lets
+// Status compare a blob against disk without writing one: the size
+// comes from the object's content length and the timestamps from
+// time.Now(), since there is no real file to stat.
+func (repo *Repo) indexFromTree(treeHash, workTree string, fromDisk bool) (*index.Index, error) {
+	blobs, err := repo.treeBlobPaths(treeHash, "")
+	if err != nil {
+		return nil, err
+	}
+
+	idx := index.New()
+	now := time.Now()
+	for _, b := range blobs {
+		entry := b
+
+		if fromDisk {
+			info, err := os.Stat(filepath.Join(workTree, b.Path))
+			if err != nil {
+				return nil, err
+			}
+			mtime := info.ModTime()
+			entry.MTimeSec, entry.MTimeNano = uint32(mtime.Unix()), uint32(mtime.Nanosecond())
+			entry.CTimeSec, entry.CTimeNano = entry.MTimeSec, entry.MTimeNano
+			entry.Size = uint32(info.Size())
+		} else {
+			obj, err := repo.ObjectParse(b.Hash)
+			if err != nil {
+				return nil, err
+			}
+			entry.MTimeSec, entry.CTimeSec = uint32(now.Unix()), uint32(now.Unix())
+			entry.MTimeNano, entry.CTimeNano = uint32(now.Nanosecond()), uint32(now.Nanosecond())
+			entry.Size = uint32(len(obj.ObjData))
+		}
+
+		idx.Add(entry)
+	}
+
+	return idx, nil
+}
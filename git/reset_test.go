@@ -0,0 +1,155 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupResetTestRepo builds a repo with two commits, each adding one
+// file on top of the last, and returns it along with its work tree
+// root and the two commit hashes (oldest first) so a test can Reset
+// back to the first one and check what moved.
+func setupResetTestRepo(t *testing.T) (repo *Repo, workTree string, commits []string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir(os.TempDir(), "testGoGitReset")
+	assertEqual(t, err, nil)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	repo, err = NewRepo(dir)
+	assertEqual(t, err, nil)
+
+	addAndCommit := func(name, data, parent string) string {
+		path := filepath.Join(dir, name)
+		assertEqual(t, ioutil.WriteFile(path, []byte(data), 0644), nil)
+		assertEqual(t, repo.Add(path), nil)
+
+		commit, err := repo.CommitIndex(parent, "commit "+name)
+		assertEqual(t, err, nil)
+		hash, err := repo.ObjectWrite(commit.Object, true)
+		assertEqual(t, err, nil)
+		assertEqual(t, repo.UpdateBranchRef(hash), nil)
+		return hash
+	}
+
+	first := addAndCommit("first.txt", "first\n", "")
+	second := addAndCommit("second.txt", "second\n", first)
+
+	return repo, dir, []string{first, second}
+}
+
+func TestResetSoftMovesRefOnly(t *testing.T) {
+	repo, workTree, commits := setupResetTestRepo(t)
+
+	assertEqual(t, repo.Reset(commits[0], SoftReset), nil)
+
+	head, err := repo.UniqueNameResolve("HEAD")
+	assertEqual(t, err, nil)
+	assertEqual(t, head, commits[0])
+
+	// A soft reset must leave the index and work tree alone: "second.txt"
+	// is still staged and still on disk even though HEAD moved past it.
+	idx, err := repo.Index()
+	assertEqual(t, err, nil)
+	_, ok := idx.Get("second.txt")
+	assertEqual(t, ok, true)
+
+	_, err = os.Stat(filepath.Join(workTree, "second.txt"))
+	assertEqual(t, err, nil)
+}
+
+func TestResetMixedRewritesIndexOnly(t *testing.T) {
+	repo, workTree, commits := setupResetTestRepo(t)
+
+	assertEqual(t, repo.Reset(commits[0], MixedReset), nil)
+
+	idx, err := repo.Index()
+	assertEqual(t, err, nil)
+	_, ok := idx.Get("second.txt")
+	assertEqual(t, ok, false)
+	_, ok = idx.Get("first.txt")
+	assertEqual(t, ok, true)
+
+	// The work tree is untouched by a mixed reset: "second.txt" is still
+	// on disk even though it's no longer staged.
+	_, err = os.Stat(filepath.Join(workTree, "second.txt"))
+	assertEqual(t, err, nil)
+}
+
+func TestResetHardRewritesWorkTree(t *testing.T) {
+	repo, workTree, commits := setupResetTestRepo(t)
+
+	assertEqual(t, repo.Reset(commits[0], HardReset), nil)
+
+	// "second.txt" must actually disappear from disk, not just be
+	// unstaged, since it doesn't exist in the target tree.
+	if _, err := os.Stat(filepath.Join(workTree, "second.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected %q to be removed by a hard reset, stat err = %v", "second.txt", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(workTree, "first.txt"))
+	assertEqual(t, err, nil)
+	assertEqual(t, string(data), "first\n")
+}
+
+func TestCheckoutWithoutForceRefusesToOverwrite(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "testGoGitCheckoutNoForce")
+	assertEqual(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	repo, err := NewRepo(dir)
+	assertEqual(t, err, nil)
+
+	blobHash, err := repo.ObjectWrite(NewObject("blob", []byte("data\n")), true)
+	assertEqual(t, err, nil)
+
+	builder := NewTreeBuilder(repo)
+	assertEqual(t, builder.Insert("file.txt", ModeFile, blobHash), nil)
+	treeHash, err := builder.Write()
+	assertEqual(t, err, nil)
+
+	assertEqual(t, ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("existing\n"), 0644), nil)
+
+	err = repo.Checkout(treeHash, dir, CheckoutOptions{})
+	if err == nil {
+		t.Fatalf("expected Checkout without Force to refuse to overwrite an existing file")
+	}
+}
+
+// TestCheckoutForceReplacesFileWithDirectory is a regression test for a
+// hard reset onto a tree that turns an existing work tree path from a
+// file into a directory: Force needs honoring for directory entries
+// too, not just blobs, or MkdirAll fails against the file in its way.
+func TestCheckoutForceReplacesFileWithDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "testGoGitCheckoutForceDir")
+	assertEqual(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	repo, err := NewRepo(dir)
+	assertEqual(t, err, nil)
+
+	blobHash, err := repo.ObjectWrite(NewObject("blob", []byte("nested\n")), true)
+	assertEqual(t, err, nil)
+
+	builder := NewTreeBuilder(repo)
+	assertEqual(t, builder.Insert("sub/file.txt", ModeFile, blobHash), nil)
+	treeHash, err := builder.Write()
+	assertEqual(t, err, nil)
+
+	// "sub" already exists as a plain file, the exact collision a hard
+	// reset needs Force to clear away before it can become a directory.
+	subPath := filepath.Join(dir, "sub")
+	assertEqual(t, ioutil.WriteFile(subPath, []byte("i am a file\n"), 0644), nil)
+
+	assertEqual(t, repo.Checkout(treeHash, dir, CheckoutOptions{Force: true}), nil)
+
+	info, err := os.Stat(subPath)
+	assertEqual(t, err, nil)
+	assertEqual(t, info.IsDir(), true)
+
+	data, err := ioutil.ReadFile(filepath.Join(subPath, "file.txt"))
+	assertEqual(t, err, nil)
+	assertEqual(t, string(data), "nested\n")
+}
@@ -0,0 +1,204 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ssrathi/gogit/git/index"
+	"github.com/ssrathi/gogit/git/trie"
+)
+
+// indexPath returns the path to ".git/index".
+func (repo *Repo) indexPath() (string, error) {
+	return repo.FilePath(false, "index")
+}
+
+// Index reads the repo's staging area, returning an empty index if
+// nothing has been staged yet.
+func (repo *Repo) Index() (*index.Index, error) {
+	path, err := repo.indexPath()
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Read(path, format.indexFormat())
+}
+
+// WriteIndex persists idx back to ".git/index".
+func (repo *Repo) WriteIndex(idx *index.Index) error {
+	path, err := repo.indexPath()
+	if err != nil {
+		return err
+	}
+
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return err
+	}
+
+	return idx.Write(path, format.indexFormat())
+}
+
+// HashFile computes the blob hash path's current contents would get if
+// staged, without writing an object to disk.
+func (repo *Repo) HashFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return repo.ObjectWrite(NewObject("blob", data), false)
+}
+
+// TreeChildren parses the direct (non-recursive) entries of the tree
+// object identified by hash, for use by the merkle trie walk.
+func (repo *Repo) TreeChildren(hash string) ([]trie.TreeChild, error) {
+	obj, err := repo.ObjectParse(hash)
+	if err != nil {
+		return nil, err
+	}
+	if obj.ObjType != "tree" {
+		return nil, fmt.Errorf("Object %q is not a tree", hash)
+	}
+
+	format, err := repo.ObjectFormat()
+	if err != nil {
+		return nil, err
+	}
+	hashSize := format.HashSize()
+
+	var children []trie.TreeChild
+	data := obj.ObjData
+	for len(data) > 0 {
+		spaceInd := bytes.IndexByte(data, ' ')
+		mode := string(data[0:spaceInd])
+
+		nameInd := bytes.IndexByte(data, '\x00')
+		name := string(data[spaceInd+1 : nameInd])
+		entryHash := fmt.Sprintf("%x", data[nameInd+1:nameInd+1+hashSize])
+
+		children = append(children, trie.TreeChild{
+			Name:  name,
+			Hash:  entryHash,
+			IsDir: mode == "40000" || mode == "040000",
+		})
+
+		data = data[nameInd+1+hashSize:]
+	}
+
+	return children, nil
+}
+
+// headTreeHash returns the tree hash of the commit HEAD currently
+// points at, or "" if there is no commit yet (a brand new repo).
+func (repo *Repo) headTreeHash() (string, error) {
+	headHash, err := repo.UniqueNameResolve("HEAD")
+	if err != nil {
+		return "", nil
+	}
+
+	obj, err := repo.ObjectParse(headHash)
+	if err != nil {
+		return "", err
+	}
+	commit, err := NewCommit(repo, obj)
+	if err != nil {
+		return "", err
+	}
+	return commit.TreeHash(), nil
+}
+
+// workTreeRoot returns the directory "gogit add"/"status" walk: the
+// parent of the repo's ".git" directory.
+func (repo *Repo) workTreeRoot() (string, error) {
+	gitDir, err := repo.FilePath(false)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(gitDir), nil
+}
+
+// Status computes every change between HEAD, the index and the work
+// tree, via the trie package's three-way diff.
+func (repo *Repo) Status() ([]trie.Change, error) {
+	workTree, err := repo.workTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := repo.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []trie.IndexEntry
+	for _, e := range idx.Entries {
+		entries = append(entries, trie.IndexEntry{Path: e.Path, Hash: e.Hash})
+	}
+
+	headTree, err := repo.headTreeHash()
+	if err != nil {
+		return nil, err
+	}
+
+	wtNode := trie.NewFilesystemRoot(workTree, repo)
+	idxNode := trie.BuildIndexTree(entries)
+	headNode := trie.NewTreeRoot(repo, headTree)
+
+	return trie.Diff(wtNode, idxNode, headNode)
+}
+
+// Add stages path: it hashes the file's current contents (writing the
+// blob object) and records a fresh index entry for it, synthesizing
+// the stat fields from the file currently on disk.
+func (repo *Repo) Add(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	hash, err := repo.ObjectWrite(NewObject("blob", data), true)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	workTree, err := repo.workTreeRoot()
+	if err != nil {
+		return err
+	}
+	relPath, err := filepath.Rel(workTree, path)
+	if err != nil {
+		return err
+	}
+
+	idx, err := repo.Index()
+	if err != nil {
+		return err
+	}
+
+	mtime := info.ModTime()
+	idx.Add(index.Entry{
+		MTimeSec:  uint32(mtime.Unix()),
+		MTimeNano: uint32(mtime.Nanosecond()),
+		CTimeSec:  uint32(mtime.Unix()),
+		CTimeNano: uint32(mtime.Nanosecond()),
+		Mode:      0100644,
+		Size:      uint32(info.Size()),
+		Hash:      hash,
+		Path:      relPath,
+	})
+
+	return repo.WriteIndex(idx)
+}
@@ -0,0 +1,254 @@
+package git
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mode is a git tree entry's mode, stored as the same string git itself
+// writes into a tree object.
+type Mode string
+
+// The tree entry modes TreeBuilder knows how to write. ModeDir is never
+// passed to Insert directly; TreeBuilder synthesizes it for every
+// intermediate directory a path implies.
+const (
+	ModeFile    Mode = "100644"
+	ModeExec    Mode = "100755"
+	ModeSymlink Mode = "120000"
+	ModeGitlink Mode = "160000"
+	ModeDir     Mode = "40000"
+)
+
+// treeBuilderEntry is a single leaf (non-directory) entry: the mode and
+// hash Insert was given for a path.
+type treeBuilderEntry struct {
+	mode Mode
+	hash string
+}
+
+// treeBuilderNode is one path segment of the radix trie TreeBuilder
+// maintains. A node is a leaf if entry is set, a directory if it has
+// children (both at once means a path collided with one of its own
+// parent directories, which Insert rejects).
+type treeBuilderNode struct {
+	entry    *treeBuilderEntry
+	children map[string]*treeBuilderNode
+}
+
+func newTreeBuilderNode() *treeBuilderNode {
+	return &treeBuilderNode{children: map[string]*treeBuilderNode{}}
+}
+
+// TreeBuilder incrementally assembles a tree object, and whatever
+// intermediate subtrees its paths need, from individual Insert/Remove
+// calls instead of a hand-assembled text blob. It replaces
+// NewTreeFromInput's whitespace-split parsing (fragile for paths
+// containing spaces, and unable to build nested trees without
+// constructing each subtree's input by hand) with a small in-memory
+// trie that Write serializes directly to binary tree entries.
+type TreeBuilder struct {
+	repo *Repo
+	root *treeBuilderNode
+}
+
+// NewTreeBuilder returns an empty TreeBuilder.
+func NewTreeBuilder(repo *Repo) *TreeBuilder {
+	return &TreeBuilder{repo: repo, root: newTreeBuilderNode()}
+}
+
+// TreeBuilderFromTree returns a TreeBuilder seeded with every entry of
+// the tree at treeHash, recursively, so a caller can mutate a handful
+// of paths (a submodule's gitlink, a single file for a single-file
+// commit) and Write a new tree without reconstructing the rest of it by
+// hand. treeHash may be "" for an empty starting tree.
+//
+// Tree entry modes finer than "file or directory" (executable, symlink,
+// gitlink) aren't recoverable from repo.TreeChildren, so loaded blob
+// entries come back as ModeFile; a caller that cares about a path's
+// exact mode should Insert over it explicitly.
+func TreeBuilderFromTree(repo *Repo, treeHash string) (*TreeBuilder, error) {
+	b := NewTreeBuilder(repo)
+	err := walkTreeBlobs(repo, treeHash, "", func(path, hash string) error {
+		return b.Insert(path, ModeFile, hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// walkTreeBlobs recursively visits every blob (non-directory) entry
+// under the tree at hash, calling visit with its full repo-relative
+// path and hash; hash may be "" for an empty tree. It exists so the
+// "recurse over repo.TreeChildren, joining prefix/name as it goes"
+// logic lives in one place for both TreeBuilderFromTree and Reset's
+// index rebuild.
+func walkTreeBlobs(repo *Repo, hash, prefix string, visit func(path, hash string) error) error {
+	if hash == "" {
+		return nil
+	}
+
+	children, err := repo.TreeChildren(hash)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		path := c.Name
+		if prefix != "" {
+			path = prefix + "/" + c.Name
+		}
+
+		if c.IsDir {
+			if err := walkTreeBlobs(repo, c.Hash, path, visit); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := visit(path, c.Hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Insert adds or replaces the leaf entry at path, creating any missing
+// intermediate directories. mode must not be ModeDir: directories are
+// synthesized from the paths inserted under them, never inserted
+// directly.
+func (b *TreeBuilder) Insert(path string, mode Mode, hash string) error {
+	if path == "" {
+		return fmt.Errorf("git: empty path")
+	}
+	if mode == ModeDir {
+		return fmt.Errorf("git: %q: directories are synthesized by TreeBuilder, not inserted", path)
+	}
+
+	segments := strings.Split(path, "/")
+	node := b.root
+	for _, seg := range segments[:len(segments)-1] {
+		child, ok := node.children[seg]
+		if !ok {
+			child = newTreeBuilderNode()
+			node.children[seg] = child
+		} else if child.entry != nil {
+			return fmt.Errorf("git: %q: %q is a file, not a directory", path, seg)
+		}
+		node = child
+	}
+
+	leaf := segments[len(segments)-1]
+	child, ok := node.children[leaf]
+	if !ok {
+		child = newTreeBuilderNode()
+		node.children[leaf] = child
+	} else if len(child.children) > 0 {
+		return fmt.Errorf("git: %q: already a directory", path)
+	}
+	child.entry = &treeBuilderEntry{mode: mode, hash: hash}
+	return nil
+}
+
+// Remove drops the leaf entry at path, if present. It doesn't prune
+// now-empty parent directories from the trie; Write simply omits a
+// directory that ends up with nothing left under it.
+func (b *TreeBuilder) Remove(path string) error {
+	segments := strings.Split(path, "/")
+	node := b.root
+	for _, seg := range segments[:len(segments)-1] {
+		child, ok := node.children[seg]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	if child, ok := node.children[segments[len(segments)-1]]; ok {
+		child.entry = nil
+	}
+	return nil
+}
+
+// Write serializes the trie into one tree object per non-empty
+// directory (deepest first) and returns the hash of the root tree.
+func (b *TreeBuilder) Write() (string, error) {
+	return b.root.write(b.repo)
+}
+
+// treeBuilderChild is one sorted, resolved entry ready to be encoded
+// into a tree object's binary form.
+type treeBuilderChild struct {
+	name string
+	mode Mode
+	hash string
+}
+
+func (n *treeBuilderNode) write(repo *Repo) (string, error) {
+	var children []treeBuilderChild
+
+	for name, child := range n.children {
+		switch {
+		case child.entry != nil:
+			children = append(children, treeBuilderChild{name: name, mode: child.entry.mode, hash: child.entry.hash})
+		case !child.hasEntries():
+			// This path, or everything under it, was Removed; git has no
+			// way to represent an empty tree entry, so it's dropped.
+		default:
+			hash, err := child.write(repo)
+			if err != nil {
+				return "", err
+			}
+			children = append(children, treeBuilderChild{name: name, mode: ModeDir, hash: hash})
+		}
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return treeSortKey(children[i]) < treeSortKey(children[j])
+	})
+
+	var data []byte
+	for _, c := range children {
+		data = append(data, []byte(c.mode)...)
+		data = append(data, ' ')
+		data = append(data, []byte(c.name)...)
+		data = append(data, 0)
+
+		raw, err := hex.DecodeString(c.hash)
+		if err != nil {
+			return "", err
+		}
+		data = append(data, raw...)
+	}
+
+	return repo.ObjectWrite(NewObject("tree", data), true)
+}
+
+// hasEntries reports whether any leaf entry remains at or under n.
+func (n *treeBuilderNode) hasEntries() bool {
+	if n.entry != nil {
+		return true
+	}
+	for _, c := range n.children {
+		if c.hasEntries() {
+			return true
+		}
+	}
+	return false
+}
+
+// treeSortKey returns c.name with a trailing "/" appended when c is a
+// directory, implementing git's tree entry ordering rule: a directory
+// sorts as if its name had a trailing slash, so the blob "foo.c" sorts
+// before the directory "foo" (since '.' < '/') even though plain string
+// comparison would put "foo" first.
+func treeSortKey(c treeBuilderChild) string {
+	if c.mode == ModeDir {
+		return c.name + "/"
+	}
+	return c.name
+}
@@ -0,0 +1,53 @@
+package git
+
+import "testing"
+
+func TestTreeBuilderInsertAndRemove(t *testing.T) {
+	b := NewTreeBuilder(nil)
+
+	assertEqual(t, b.Insert("dir/sub/file.txt", ModeFile, "aaaa"), nil)
+	assertEqual(t, b.Insert("dir/other.txt", ModeExec, "bbbb"), nil)
+	assertEqual(t, b.Insert("top.txt", ModeFile, "cccc"), nil)
+
+	dir := b.root.children["dir"]
+	if dir == nil || dir.entry != nil {
+		t.Fatalf("expected %q to be a directory node", "dir")
+	}
+	sub := dir.children["sub"]
+	if sub == nil || sub.children["file.txt"] == nil {
+		t.Fatalf("expected %q to be inserted", "dir/sub/file.txt")
+	}
+
+	assertEqual(t, b.Remove("dir/other.txt"), nil)
+	if dir.children["other.txt"].entry != nil {
+		t.Fatalf("expected %q to be removed", "dir/other.txt")
+	}
+}
+
+func TestTreeBuilderInsertRejectsModeDir(t *testing.T) {
+	b := NewTreeBuilder(nil)
+	if err := b.Insert("dir", ModeDir, "aaaa"); err == nil {
+		t.Fatalf("expected an error inserting with ModeDir")
+	}
+}
+
+func TestTreeBuilderInsertRejectsFileAsDirectory(t *testing.T) {
+	b := NewTreeBuilder(nil)
+	assertEqual(t, b.Insert("foo", ModeFile, "aaaa"), nil)
+
+	if err := b.Insert("foo/bar", ModeFile, "bbbb"); err == nil {
+		t.Fatalf("expected an error inserting under a file path component")
+	}
+}
+
+func TestTreeSortOrdering(t *testing.T) {
+	// Git's tree ordering rule: a directory sorts as if its name had a
+	// trailing "/", so the blob "foo.c" sorts before the directory
+	// "foo" even though plain string comparison would put "foo" first.
+	file := treeBuilderChild{name: "foo.c", mode: ModeFile}
+	dir := treeBuilderChild{name: "foo", mode: ModeDir}
+
+	if !(treeSortKey(file) < treeSortKey(dir)) {
+		t.Fatalf("expected %q to sort before %q", file.name, dir.name+"/")
+	}
+}
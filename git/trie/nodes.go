@@ -0,0 +1,228 @@
+package trie
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Hasher computes the git blob hash a file's current on-disk contents
+// would get if staged, without actually writing an object. It is
+// implemented by *git.Repo; kept as an interface here so this package
+// has no dependency on the git package (which depends on this one).
+type Hasher interface {
+	HashFile(path string) (string, error)
+}
+
+// FilesystemNode is a Node backed by a directory on disk. Directories
+// are walked lazily (Children only reads the directory when called)
+// and files are hashed lazily the same way, so a status check that
+// never needs to look past the first differing top-level entry never
+// pays for the rest of the tree.
+type FilesystemNode struct {
+	name    string
+	path    string
+	isDir   bool
+	hasher  Hasher
+	ignore  *ignoreList
+	hash    string
+	hashed  bool
+}
+
+// NewFilesystemRoot builds the FilesystemNode for the work tree root at
+// dir, loading ".gitignore" if present.
+func NewFilesystemRoot(dir string, hasher Hasher) *FilesystemNode {
+	return &FilesystemNode{
+		name:   "",
+		path:   dir,
+		isDir:  true,
+		hasher: hasher,
+		ignore: loadIgnoreList(dir),
+	}
+}
+
+func (n *FilesystemNode) Name() string { return n.name }
+func (n *FilesystemNode) IsDir() bool  { return n.isDir }
+
+func (n *FilesystemNode) Hash() string {
+	if n.isDir {
+		return ""
+	}
+	if !n.hashed {
+		n.hash, _ = n.hasher.HashFile(n.path)
+		n.hashed = true
+	}
+	return n.hash
+}
+
+func (n *FilesystemNode) Children() ([]Node, error) {
+	entries, err := ioutil.ReadDir(n.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []Node
+	for _, e := range entries {
+		if e.Name() == ".git" || n.ignore.matches(e.Name()) {
+			continue
+		}
+
+		children = append(children, &FilesystemNode{
+			name:   e.Name(),
+			path:   filepath.Join(n.path, e.Name()),
+			isDir:  e.IsDir(),
+			hasher: n.hasher,
+			ignore: n.ignore,
+		})
+	}
+
+	return children, nil
+}
+
+// ignoreList is a minimal ".gitignore" matcher: one glob pattern per
+// non-empty, non-comment line, matched against the bare file/dir name.
+// Directory-only ("trailing /") and negated ("leading !") patterns and
+// nested .gitignore files are not supported.
+type ignoreList struct {
+	patterns []string
+}
+
+func loadIgnoreList(dir string) *ignoreList {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return &ignoreList{}
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.TrimSuffix(line, "/"))
+	}
+	return &ignoreList{patterns: patterns}
+}
+
+func (l *ignoreList) matches(name string) bool {
+	if l == nil {
+		return false
+	}
+	for _, p := range l.patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexEntry is the minimal piece of a git/index.Entry the trie cares
+// about, kept separate so this package doesn't need to import
+// git/index just for the Entry type.
+type IndexEntry struct {
+	Path string
+	Hash string
+}
+
+// IndexNode is a Node backed by the flat, path-sorted entry list of a
+// staging index. Since the index has no notion of directories, an
+// IndexNode's intermediate directories exist only in memory,
+// synthesized by grouping entries by path prefix, and have no hash
+// (Hash() is ""); only leaf entries carry a real git object hash.
+type IndexNode struct {
+	name     string
+	isDir    bool
+	hash     string
+	children map[string]*IndexNode
+	order    []string
+}
+
+// BuildIndexTree turns a flat index entry list into the IndexNode tree
+// rooted at "".
+func BuildIndexTree(entries []IndexEntry) *IndexNode {
+	root := &IndexNode{isDir: true, children: map[string]*IndexNode{}}
+	for _, e := range entries {
+		root.insert(strings.Split(e.Path, "/"), e.Hash)
+	}
+	return root
+}
+
+func (n *IndexNode) insert(segments []string, hash string) {
+	head := segments[0]
+	child, ok := n.children[head]
+	if !ok {
+		child = &IndexNode{name: head, children: map[string]*IndexNode{}}
+		n.children[head] = child
+		n.order = append(n.order, head)
+	}
+
+	if len(segments) == 1 {
+		child.hash = hash
+		return
+	}
+
+	child.isDir = true
+	child.insert(segments[1:], hash)
+}
+
+func (n *IndexNode) Name() string { return n.name }
+func (n *IndexNode) IsDir() bool  { return n.isDir }
+func (n *IndexNode) Hash() string { return n.hash }
+
+func (n *IndexNode) Children() ([]Node, error) {
+	children := make([]Node, len(n.order))
+	for i, name := range n.order {
+		children[i] = n.children[name]
+	}
+	return children, nil
+}
+
+// TreeReader resolves a committed tree object's direct children,
+// implemented by *git.Repo. Kept as an interface for the same reason as
+// Hasher: this package must not import git.
+type TreeReader interface {
+	TreeChildren(hash string) ([]TreeChild, error)
+}
+
+// TreeChild is one entry of a parsed git tree object.
+type TreeChild struct {
+	Name  string
+	Hash  string
+	IsDir bool
+}
+
+// TreeNode is a Node backed by a committed git tree object.
+type TreeNode struct {
+	name   string
+	hash   string
+	isDir  bool
+	reader TreeReader
+}
+
+// NewTreeRoot builds the TreeNode for the tree object identified by
+// hash (typically HEAD's tree). hash may be "" to represent an empty
+// tree (e.g. there is no HEAD yet).
+func NewTreeRoot(reader TreeReader, hash string) *TreeNode {
+	return &TreeNode{hash: hash, isDir: true, reader: reader}
+}
+
+func (n *TreeNode) Name() string { return n.name }
+func (n *TreeNode) IsDir() bool  { return n.isDir }
+func (n *TreeNode) Hash() string { return n.hash }
+
+func (n *TreeNode) Children() ([]Node, error) {
+	if n.hash == "" {
+		return nil, nil
+	}
+
+	entries, err := n.reader.TreeChildren(n.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]Node, len(entries))
+	for i, e := range entries {
+		children[i] = &TreeNode{name: e.Name, hash: e.Hash, isDir: e.IsDir, reader: n.reader}
+	}
+	return children, nil
+}
@@ -0,0 +1,200 @@
+/*
+Package trie implements the three-way merkle-trie abstraction gogit's
+"status"/"add"/commit-from-index flow is built on: the work tree, the
+staging index and a committed tree are each exposed as a Node, and
+Diff performs a single synchronized pre-order walk across all three to
+produce the set of changes between them.
+
+Diff is deliberately generic over where a Node's data actually lives
+(disk, the index file, or a git tree object) so the same walk drives
+"status" (work tree vs. index vs. HEAD) without three separate diff
+implementations.
+*/
+package trie
+
+import "sort"
+
+// Node is one entry (file or directory) in one of the three tries.
+// Children is only called on directories, and may return them in any
+// order: Diff sorts each level by name itself.
+type Node interface {
+	Name() string
+	Hash() string
+	IsDir() bool
+	Children() ([]Node, error)
+}
+
+// Side identifies which pair of tries a Change was found between.
+type Side int
+
+// The two comparisons a git status performs: work tree against the
+// index (unstaged changes/untracked files) and index against HEAD
+// (staged changes).
+const (
+	WorkTreeIndex Side = iota
+	IndexHead
+)
+
+func (s Side) String() string {
+	if s == WorkTreeIndex {
+		return "worktree/index"
+	}
+	return "index/HEAD"
+}
+
+// Action is the kind of change a Change describes.
+type Action int
+
+const (
+	Added Action = iota
+	Modified
+	Deleted
+)
+
+func (a Action) String() string {
+	switch a {
+	case Added:
+		return "Added"
+	case Modified:
+		return "Modified"
+	default:
+		return "Deleted"
+	}
+}
+
+// Change is a single difference found by Diff.
+type Change struct {
+	Path   string
+	Side   Side
+	Action Action
+}
+
+// Diff walks workTree, index and head in lock step and returns every
+// difference found. head may be nil (an empty tree, as in the initial
+// commit case); workTree and index must not be.
+func Diff(workTree, index, head Node) ([]Change, error) {
+	var changes []Change
+	err := diffLevel(workTree, index, head, "", &changes)
+	return changes, err
+}
+
+// diffLevel compares the children of three (possibly absent, indicated
+// by a nil Node) directory nodes that share the path "prefix".
+func diffLevel(workTree, index, head Node, prefix string, changes *[]Change) error {
+	wtChildren, err := childrenOf(workTree)
+	if err != nil {
+		return err
+	}
+	idxChildren, err := childrenOf(index)
+	if err != nil {
+		return err
+	}
+	headChildren, err := childrenOf(head)
+	if err != nil {
+		return err
+	}
+
+	i, j, k := 0, 0, 0
+	for i < len(wtChildren) || j < len(idxChildren) || k < len(headChildren) {
+		name := nextName(wtChildren, i, idxChildren, j, headChildren, k)
+
+		var wt, idx, head Node
+		if i < len(wtChildren) && wtChildren[i].Name() == name {
+			wt = wtChildren[i]
+			i++
+		}
+		if j < len(idxChildren) && idxChildren[j].Name() == name {
+			idx = idxChildren[j]
+			j++
+		}
+		if k < len(headChildren) && headChildren[k].Name() == name {
+			head = headChildren[k]
+			k++
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "/" + name
+		}
+
+		if err := diffEntry(wt, idx, head, path, changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffEntry compares a single (work tree, index, HEAD) triple, all of
+// which describe the same path (a nil entry means "absent on that
+// side"). A directory entry never gets a Change of its own — Added,
+// Modified and Deleted only ever describe leaf (blob) paths, matching
+// what "git status" prints — it only recurses into matching
+// directories, since IndexNode has no real hash for a directory
+// (Hash() is always "") and would otherwise spuriously diff as
+// Modified against a TreeNode's real tree hash on every ancestor
+// directory of any change.
+func diffEntry(wt, idx, head Node, path string, changes *[]Change) error {
+	if isDir(wt) || isDir(idx) || isDir(head) {
+		return diffLevel(wt, idx, head, path, changes)
+	}
+
+	// Index vs. HEAD: whether this path is staged, and how.
+	switch {
+	case idx == nil && head != nil:
+		*changes = append(*changes, Change{Path: path, Side: IndexHead, Action: Deleted})
+	case idx != nil && head == nil:
+		*changes = append(*changes, Change{Path: path, Side: IndexHead, Action: Added})
+	case idx != nil && head != nil && idx.Hash() != head.Hash():
+		*changes = append(*changes, Change{Path: path, Side: IndexHead, Action: Modified})
+	}
+
+	// Work tree vs. index: unstaged edits and untracked files.
+	switch {
+	case wt == nil && idx != nil:
+		*changes = append(*changes, Change{Path: path, Side: WorkTreeIndex, Action: Deleted})
+	case wt != nil && idx == nil:
+		*changes = append(*changes, Change{Path: path, Side: WorkTreeIndex, Action: Added})
+	case wt != nil && idx != nil && wt.Hash() != idx.Hash():
+		*changes = append(*changes, Change{Path: path, Side: WorkTreeIndex, Action: Modified})
+	}
+
+	return nil
+}
+
+func isDir(n Node) bool {
+	return n != nil && n.IsDir()
+}
+
+func childrenOf(n Node) ([]Node, error) {
+	if n == nil || !n.IsDir() {
+		return nil, nil
+	}
+	children, err := n.Children()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+	return children, nil
+}
+
+// nextName returns the lexicographically smallest name among the three
+// iterators' current positions.
+func nextName(wt []Node, i int, idx []Node, j int, head []Node, k int) string {
+	var name string
+	consider := func(n Node) {
+		if name == "" || n.Name() < name {
+			name = n.Name()
+		}
+	}
+	if i < len(wt) {
+		consider(wt[i])
+	}
+	if j < len(idx) {
+		consider(idx[j])
+	}
+	if k < len(head) {
+		consider(head[k])
+	}
+	return name
+}
@@ -0,0 +1,81 @@
+package trie
+
+import (
+	"sort"
+	"testing"
+)
+
+type fakeTreeReader map[string][]TreeChild
+
+func (f fakeTreeReader) TreeChildren(hash string) ([]TreeChild, error) {
+	return f[hash], nil
+}
+
+func assertChanges(t *testing.T, got []Change, want []Change) {
+	t.Helper()
+
+	sortChanges := func(cs []Change) {
+		sort.Slice(cs, func(i, j int) bool {
+			if cs[i].Path != cs[j].Path {
+				return cs[i].Path < cs[j].Path
+			}
+			return cs[i].Side < cs[j].Side
+		})
+	}
+	sortChanges(got)
+	sortChanges(want)
+
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	}
+}
+
+// TestDiffNewFile covers a file staged in the index but never
+// committed: it should show up as Added on the index/HEAD side only.
+func TestDiffNewFile(t *testing.T) {
+	head := NewTreeRoot(fakeTreeReader{}, "")
+	index := BuildIndexTree([]IndexEntry{
+		{Path: "README.md", Hash: "aaaa"},
+	})
+
+	changes, err := Diff(index, index, head)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	assertChanges(t, changes, []Change{
+		{Path: "README.md", Side: IndexHead, Action: Added},
+	})
+}
+
+// TestDiffModifiedNested covers a change two directories deep, and
+// confirms paths not on that branch are left untouched.
+func TestDiffModifiedNested(t *testing.T) {
+	reader := fakeTreeReader{
+		"root-hash": {
+			{Name: "src", Hash: "src-hash-old", IsDir: true},
+			{Name: "README.md", Hash: "readme-hash"},
+		},
+		"src-hash-old": {
+			{Name: "main.go", Hash: "main-hash-old"},
+		},
+	}
+	head := NewTreeRoot(reader, "root-hash")
+
+	index := BuildIndexTree([]IndexEntry{
+		{Path: "src/main.go", Hash: "main-hash-new"},
+		{Path: "README.md", Hash: "readme-hash"},
+	})
+
+	changes, err := Diff(index, index, head)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	assertChanges(t, changes, []Change{
+		{Path: "src/main.go", Side: IndexHead, Action: Modified},
+	})
+}